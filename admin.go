@@ -0,0 +1,135 @@
+package vroomy
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ListenerAdmin is the name of the admin listener
+const ListenerAdmin = "admin"
+
+// AdminConfig is the configuration of the admin listener, which serves its own routes and groups
+// (e.g. health, metrics and pprof) on an address which is not exposed publicly
+type AdminConfig struct {
+	// Address to bind, either TCP ("127.0.0.1:9090") or a Unix domain socket ("unix:///run/admin.sock")
+	Address string `toml:"address"`
+	// SocketMode and SocketOwner of the admin Unix domain socket, default to the settings of the service
+	SocketMode  string `toml:"socketMode"`
+	SocketOwner string `toml:"socketOwner"`
+	// Token, when set, is required as a bearer token (Authorization: Bearer <token>) for every admin request
+	Token string `toml:"token"`
+
+	// Groups are the admin route groups
+	Groups []*RouteGroup `toml:"group"`
+	// Routes are the admin routes
+	Routes []*Route `toml:"route"`
+}
+
+// config will return a configuration containing the admin routes and groups
+func (a *AdminConfig) config() *Config {
+	var c Config
+	c.Groups = a.Groups
+	c.Routes = a.Routes
+	return &c
+}
+
+func (a *AdminConfig) validate() (err error) {
+	if len(a.Address) == 0 {
+		return fmt.Errorf("invalid admin config, address cannot be empty")
+	}
+
+	if _, _, err = parseListenAddress(a.Address); err != nil {
+		return fmt.Errorf("invalid admin config: %v", err)
+	}
+
+	if len(a.SocketMode) > 0 {
+		if _, err = parseSocketMode(a.SocketMode); err != nil {
+			return fmt.Errorf("invalid admin config: %v", err)
+		}
+	}
+
+	return
+}
+
+func (a *AdminConfig) validateRoutes() (err error) {
+	if err = a.config().validateRoutes(); err != nil {
+		return fmt.Errorf("invalid admin config: %v", err)
+	}
+
+	return
+}
+
+func (a *AdminConfig) resolvePaths(c *Config) {
+	a.Address = c.resolveListenAddress(a.Address)
+	for _, r := range a.Routes {
+		r.Target = c.resolvePath(r.Target)
+	}
+}
+
+// getAdminListener will return the admin listener, or nil when it is not configured
+func getAdminListener(c *Config) *Listener {
+	if c.Admin == nil {
+		return nil
+	}
+
+	return c.Admin.listener()
+}
+
+func (a *AdminConfig) listener() *Listener {
+	return &Listener{
+		Name:        ListenerAdmin,
+		Address:     a.Address,
+		SocketMode:  a.SocketMode,
+		SocketOwner: a.SocketOwner,
+	}
+}
+
+// initAdmin will initialize the admin router, when an admin listener has been configured
+func (v *Vroomy) initAdmin(cfg *Config) (srv *router, err error) {
+	if cfg.Admin == nil {
+		return
+	}
+
+	acfg := cfg.Admin.config()
+	srv = newRouter()
+	srv.SetOnError(v.cfg.ErrorLogger)
+	if err = v.initGroups(acfg, srv); err != nil {
+		err = fmt.Errorf("error initializing admin groups: %v", err)
+		return
+	}
+
+	if err = v.initRoutes(acfg, srv); err != nil {
+		err = fmt.Errorf("error initializing admin routes: %v", err)
+		return
+	}
+
+	return
+}
+
+// getAdminHandler will return the handler of the admin listener, requiring the token when one is configured
+func (v *Vroomy) getAdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		v.mu.RLock()
+		token := v.cfg.Admin.Token
+		v.mu.RUnlock()
+
+		if len(token) > 0 && !isValidBearerToken(req, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		v.admin.ServeHTTP(w, req)
+	})
+}
+
+func isValidBearerToken(req *http.Request, token string) bool {
+	provided, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
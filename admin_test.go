@@ -0,0 +1,166 @@
+package vroomy
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAdminConfig_validate(t *testing.T) {
+	type testcase struct {
+		admin       AdminConfig
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{
+			admin: AdminConfig{Address: "127.0.0.1:9090", Routes: []*Route{{HTTPPath: "/health", Handlers: []string{"a.Hello"}}}},
+		},
+		{
+			admin:       AdminConfig{},
+			expectedErr: "invalid admin config, address cannot be empty",
+		},
+		{
+			admin:       AdminConfig{Address: "9090"},
+			expectedErr: "invalid admin config: invalid listen address <9090>: address 9090: missing port in address",
+		},
+		{
+			admin:       AdminConfig{Address: "unix:///run/admin.sock", SocketMode: "rw"},
+			expectedErr: "invalid admin config: invalid socket mode <rw>, expected octal file mode (e.g. \"0660\")",
+		},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(tc.admin.validate()); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func TestVroomy_Listen_admin(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Port = 0
+	cfg.Listen = "127.0.0.1:0"
+	cfg.Admin = &AdminConfig{
+		Address: "127.0.0.1:0",
+		Token:   "secret",
+		Routes:  []*Route{{Method: "GET", HTTPPath: "/health", Handlers: []string{"a.Hello"}}},
+	}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+
+	awaitReady(t, v)
+	addr := v.describeListener(cfg.Admin.listener())
+	expectStatus(t, "http://"+addr+"/health", http.StatusUnauthorized)
+	expectStatus(t, "http://"+addr+"/hello", http.StatusUnauthorized)
+
+	type testcase struct {
+		path          string
+		authorization string
+		expected      int
+	}
+
+	tcs := []testcase{
+		{path: "/health", authorization: "Bearer secret", expected: http.StatusOK},
+		{path: "/health", authorization: "Bearer other", expected: http.StatusUnauthorized},
+		{path: "/health", authorization: "secret", expected: http.StatusUnauthorized},
+		{path: "/hello", authorization: "Bearer secret", expected: http.StatusNotFound},
+	}
+
+	for _, tc := range tcs {
+		req, err := http.NewRequest(http.MethodGet, "http://"+addr+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Authorization", tc.authorization)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+		if resp.StatusCode != tc.expected {
+			t.Fatalf("invalid status code for <%s>, expected %d and received %d", tc.path, tc.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestVroomy_Listen_adminUnix(t *testing.T) {
+	type testcase struct {
+		socketMode      string
+		adminSocketMode string
+		expectedMode    os.FileMode
+	}
+
+	tcs := []testcase{
+		{socketMode: "0660", expectedMode: 0660},
+		{socketMode: "0660", adminSocketMode: "0600", expectedMode: 0600},
+	}
+
+	for _, tc := range tcs {
+		r := NewRegistry()
+		if err := r.Register("a", &handlerPlugin{}); err != nil {
+			t.Fatal(err)
+		}
+
+		dir := t.TempDir()
+		cfg := newTestConfig(t, "a.Hello")
+		cfg.Dir = dir
+		cfg.Listen = "unix://app.sock"
+		cfg.SocketMode = tc.socketMode
+		cfg.Admin = &AdminConfig{
+			Address:    "unix://admin.sock",
+			SocketMode: tc.adminSocketMode,
+			Routes:     []*Route{{Method: "GET", HTTPPath: "/health", Handlers: []string{"a.Hello"}}},
+		}
+
+		v, err := NewWithRegistry(cfg, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go v.Listen(ctx)
+
+		loc := filepath.Join(dir, "admin.sock")
+		deadline := time.Now().Add(time.Second)
+		for v.describeListener(&Listener{Name: ListenerAdmin}) != unixPrefix+loc {
+			if time.Now().After(deadline) {
+				t.Fatalf("invalid listener, expected socket <%s> to be bound", loc)
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		info, err := os.Stat(loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mode := info.Mode().Perm(); mode != tc.expectedMode {
+			t.Fatalf("invalid socket mode, expected %v and received %v", tc.expectedMode, mode)
+		}
+
+		cancel()
+		if err = v.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
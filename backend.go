@@ -0,0 +1,25 @@
+package vroomy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Backend will return the backend of the plugin with the provided key, asserted as T
+// T must either be the exact type of the backend, or an interface the backend implements
+// Note: Backends are cached once the plugin has been loaded
+func Backend[T any](v *Vroomy, key string) (backend T, err error) {
+	var reference interface{}
+	if reference, err = v.getReference(key); err != nil {
+		return
+	}
+
+	target := reflect.ValueOf(&backend).Elem()
+	if err = canSet(target, reflect.ValueOf(reference)); err != nil {
+		err = fmt.Errorf("cannot get backend for plugin <%s>: %v", key, err)
+		return
+	}
+
+	backend = reference.(T)
+	return
+}
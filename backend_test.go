@@ -0,0 +1,67 @@
+package vroomy
+
+import (
+	"testing"
+)
+
+type countingPlugin struct {
+	BasePlugin
+
+	calls int
+}
+
+func (c *countingPlugin) Backend() interface{} {
+	c.calls++
+	return c
+}
+
+func TestBackend(t *testing.T) {
+	r := NewRegistry()
+	cp := &countingPlugin{}
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("counting", cp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("empty", &BasePlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := cp.calls
+	for i := 0; i < 3; i++ {
+		var backend *countingPlugin
+		if backend, err = Backend[*countingPlugin](v, "counting"); err != nil {
+			t.Fatal(err)
+		}
+
+		if backend != cp {
+			t.Fatalf("invalid backend, expected %p and received %p", cp, backend)
+		}
+	}
+
+	if cp.calls != calls {
+		t.Fatalf("invalid number of Backend calls, expected %d and received %d", calls, cp.calls)
+	}
+
+	if _, err = Backend[Plugin](v, "counting"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "cannot get backend for plugin <counting>: invalid type, expected vroomy.testGreeter and received *vroomy.countingPlugin"
+	if _, err = Backend[testGreeter](v, "counting"); err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+
+	want = "cannot call backend for plugin <empty>, provided value is nil"
+	if _, err = Backend[Plugin](v, "empty"); err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+}
@@ -0,0 +1,184 @@
+package vroomy
+
+import (
+	"fmt"
+
+	"github.com/gdbu/errors"
+)
+
+// NewBuilder will return a new Builder with an empty configuration
+func NewBuilder() *Builder {
+	var cfg Config
+	cfg.Environment = make(map[string]string)
+	cfg.populateFromOSEnv()
+	return NewBuilderFromConfig(&cfg)
+}
+
+// NewBuilderFromConfig will return a new Builder which extends the provided configuration
+// This allows a loaded configuration to be mixed with programmatic settings
+func NewBuilderFromConfig(cfg *Config) *Builder {
+	var b Builder
+	b.cfg = cfg
+	if b.cfg.Environment == nil {
+		b.cfg.Environment = make(map[string]string)
+	}
+
+	return &b
+}
+
+// Builder is a programmatic alternative to the TOML configuration:
+//
+//	svc, err := vroomy.NewBuilder().
+//		Port(8080).
+//		Plugin("users", u).
+//		Group("api", "/api", "auth.Check").
+//		GroupRoute("api", "GET", "/users/:id", "users.Get").
+//		Build()
+type Builder struct {
+	cfg *Config
+	r   *Registry
+
+	errs errors.ErrorList
+}
+
+// Name will set the service name
+func (b *Builder) Name(name string) *Builder {
+	b.cfg.Name = name
+	return b
+}
+
+// Dir will set the service directory
+func (b *Builder) Dir(dir string) *Builder {
+	b.cfg.Dir = dir
+	return b
+}
+
+// Port will set the HTTP port
+func (b *Builder) Port(port uint16) *Builder {
+	b.cfg.Port = port
+	return b
+}
+
+// TLSPort will set the HTTPS port
+func (b *Builder) TLSPort(port uint16) *Builder {
+	b.cfg.TLSPort = port
+	return b
+}
+
+// TLSDir will set the TLS certificate directory
+func (b *Builder) TLSDir(dir string) *Builder {
+	b.cfg.TLSDir = dir
+	return b
+}
+
+// AllowNonTLS will set whether or not non-TLS requests are served when TLS is enabled
+func (b *Builder) AllowNonTLS(allow bool) *Builder {
+	b.cfg.AllowNonTLS = allow
+	return b
+}
+
+// AutoCert will set the autocert directory and hosts
+func (b *Builder) AutoCert(dir string, hosts ...string) *Builder {
+	b.cfg.AutoCertDir = dir
+	b.cfg.AutoCertHosts = hosts
+	return b
+}
+
+// Env will set an environment value, which is kept when a loaded configuration is reloaded
+func (b *Builder) Env(key, value string) *Builder {
+	b.cfg.setEnvOverride(key, value)
+	return b
+}
+
+// ErrorLogger will set the error logger
+func (b *Builder) ErrorLogger(fn func(error)) *Builder {
+	b.cfg.ErrorLogger = fn
+	return b
+}
+
+// Registry will set the plugin registry used to build the service
+func (b *Builder) Registry(r *Registry) *Builder {
+	b.r = r
+	return b
+}
+
+// Plugin will register a plugin to the builder's registry
+// Note: A new registry is created on the first call unless one has been set using Registry
+func (b *Builder) Plugin(key string, pi Plugin) *Builder {
+	if b.r == nil {
+		b.r = NewRegistry()
+	}
+
+	b.errs.Push(b.r.Register(key, pi))
+	return b
+}
+
+// Group will add a route group
+func (b *Builder) Group(name, httpPath string, handlers ...string) *Builder {
+	return b.SubGroup("", name, httpPath, handlers...)
+}
+
+// SubGroup will add a route group nested within a parent group
+func (b *Builder) SubGroup(parent, name, httpPath string, handlers ...string) *Builder {
+	var g RouteGroup
+	g.Name = name
+	g.Group = parent
+	g.HTTPPath = httpPath
+	g.Handlers = handlers
+	b.cfg.Groups = append(b.cfg.Groups, &g)
+	return b
+}
+
+// Route will add a route
+func (b *Builder) Route(method, httpPath string, handlers ...string) *Builder {
+	return b.GroupRoute("", method, httpPath, handlers...)
+}
+
+// GroupRoute will add a route within a group
+func (b *Builder) GroupRoute(group, method, httpPath string, handlers ...string) *Builder {
+	var r Route
+	r.Group = group
+	r.Method = method
+	r.HTTPPath = httpPath
+	r.Handlers = handlers
+	b.cfg.Routes = append(b.cfg.Routes, &r)
+	return b
+}
+
+// Config will validate and return the built configuration
+// Note: The configuration is validated as it is when a service is created from a configuration, call
+// Config.Validate on the returned configuration to validate routes and groups strictly
+func (b *Builder) Config() (cfg *Config, err error) {
+	if err = b.errs.Err(); err != nil {
+		return
+	}
+
+	if b.cfg.Dir == "" {
+		b.cfg.Dir = "./"
+	}
+
+	b.cfg.setDefaultDataDir()
+	if err = b.cfg.validateSettings(); err != nil {
+		err = fmt.Errorf("error validating config: %v", err)
+		return
+	}
+
+	cfg = b.cfg
+	return
+}
+
+// Build will validate the configuration and return a new instance of service
+// Note: When no plugins have been provided to the builder, the default registry is used
+func (b *Builder) Build() (v *Vroomy, err error) {
+	var cfg *Config
+	if cfg, err = b.Config(); err != nil {
+		return
+	}
+
+	r := b.r
+	if r == nil {
+		r = defaultRegistry
+	}
+
+	return NewWithRegistry(cfg, r)
+}
@@ -0,0 +1,111 @@
+package vroomy
+
+import (
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	v, err := NewBuilder().
+		Port(8080).
+		Env("dataDir", t.TempDir()).
+		Plugin("a", &handlerPlugin{}).
+		Group("api", "/api", "a.Hello").
+		SubGroup("api", "v1", "/v1").
+		Route("GET", "/hello", "a.Hello").
+		GroupRoute("v1", "POST", "/hello", "a.Hello").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Port() != 8080 {
+		t.Fatalf("invalid port, expected %d and received %d", 8080, v.Port())
+	}
+
+	g, err := v.cfg.GetRouteGroup("v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.G == nil {
+		t.Fatal("invalid group, expected group to be initialized")
+	}
+}
+
+func TestBuilder_validation(t *testing.T) {
+	type testcase struct {
+		name    string
+		b       *Builder
+		wantErr string
+	}
+
+	tcs := []testcase{
+		{
+			name:    "duplicate plugin",
+			b:       NewBuilder().Plugin("a", &handlerPlugin{}).Plugin("a", &handlerPlugin{}),
+			wantErr: "plugin with the key of <a> has already been loaded",
+		},
+		{
+			name:    "missing group",
+			b:       NewBuilder().GroupRoute("api", "GET", "/hello", "a.Hello"),
+			wantErr: "invalid route { HTTPPath: \"/hello\", Target: \"\" Plugin Handler: \"[a.Hello]\" }, group <api>: group not found",
+		},
+		{
+			name:    "invalid method",
+			b:       NewBuilder().Route("FETCH", "/hello", "a.Hello"),
+			wantErr: "invalid route { HTTPPath: \"/hello\", Target: \"\" Plugin Handler: \"[a.Hello]\" }, method of <FETCH> is not supported",
+		},
+		{
+			name:    "invalid handler",
+			b:       NewBuilder().Route("GET", "/hello", "hello"),
+			wantErr: "invalid route { HTTPPath: \"/hello\", Target: \"\" Plugin Handler: \"[hello]\" }: expected key and handler, received \"hello\"",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Routes and groups are only validated strictly when Validate is called
+			cfg, err := tc.b.Config()
+			if err == nil {
+				err = cfg.Validate()
+			}
+
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("invalid error, expected <%s> and received <%v>", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewWithRegistry_lenientRoutes(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Routes and groups which are rejected by Validate are still served when created from a configuration
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Groups = []*RouteGroup{{Name: "api", HTTPPath: "/api"}, {HTTPPath: "/unnamed", Handlers: []string{"a.Hello"}}}
+	cfg.Routes = append(cfg.Routes, &Route{Method: "PATCH", HTTPPath: "/patch", Handlers: []string{"a.Hello"}})
+	cfg.Routes = append(cfg.Routes, &Route{Group: "api", Handlers: []string{"a.Hello"}})
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("invalid error, expected error and received nil")
+	}
+
+	if _, err := NewWithRegistry(cfg, r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewBuilderFromConfig_lenientRoutes(t *testing.T) {
+	// Configurations which are accepted by NewWithConfig are also accepted when extended with a builder
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Routes = append(cfg.Routes, &Route{Method: "PATCH", HTTPPath: "/patch", Handlers: []string{"a.Hello"}})
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("invalid error, expected error and received nil")
+	}
+
+	if _, err := NewBuilderFromConfig(cfg).Plugin("a", &handlerPlugin{}).Route("GET", "/extended", "a.Hello").Build(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,286 @@
+package vroomy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdbu/errors"
+)
+
+const (
+	// ErrNoCertificates is returned when a TLS directory does not contain any certificate pairs
+	ErrNoCertificates = errors.Error("no certificate pairs found")
+
+	// certificateWarningPeriod is how long before expiration a warning is logged for a certificate
+	certificateWarningPeriod = time.Hour * 24 * 30
+	// certificateWarningInterval is how often expiring certificates are logged while listening
+	certificateWarningInterval = time.Hour * 24
+)
+
+// newCertificateStore will return a certificate store with the certificates loaded from the provided directory
+func newCertificateStore(dir string) (cs *certificateStore, err error) {
+	cs = &certificateStore{dir: dir}
+	if err = cs.load(true); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// certificateStore serves the certificate pairs within a TLS directory, which can be reloaded and swapped
+// for new handshakes while connections using the previous certificates continue uninterrupted
+type certificateStore struct {
+	mu sync.Mutex
+
+	dir   string
+	certs atomic.Pointer[[]tls.Certificate]
+	// Modification times of the certificate and key files which were loaded
+	modified map[string]time.Time
+}
+
+// load will load and validate the certificate pairs of the directory, the current certificates are kept if any pair is invalid
+// Note: When the certificates are initially loaded, pairs which are not yet valid or have expired are logged and skipped
+func (cs *certificateStore) load(initial bool) (err error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	// Modification times are updated before loading, so invalid pairs are only reported once per modification
+	cs.modified = getModTimes(getTLSCertificateFiles(cs.dir))
+
+	var certs []tls.Certificate
+	if certs, err = loadTLSCertificates(cs.dir); err != nil {
+		return
+	}
+
+	if initial {
+		certs = getValidCertificates(certs, time.Now())
+	}
+
+	if err = validateCertificates(certs, time.Now()); err != nil {
+		return
+	}
+
+	cs.certs.Store(&certs)
+	for _, cert := range certs {
+		log.Printf("Vroomy: Loaded certificate for %s, expires %s", getCertificateNames(cert), cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	cs.warnExpiring(time.Now())
+	return
+}
+
+// isModified will return whether or not the certificate pairs have been modified since they were loaded
+func (cs *certificateStore) isModified() bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return !maps.Equal(cs.modified, getModTimes(getTLSCertificateFiles(cs.dir)))
+}
+
+// getTLSCertificateFiles will return the certificate and key files within a directory
+func getTLSCertificateFiles(dir string) (files []string) {
+	// Note: Directories which cannot be read have no files, they are reported when the certificates are loaded
+	pairs, _ := getTLSCertificatePairs(dir)
+	for _, pair := range pairs {
+		for _, loc := range pair {
+			if len(loc) > 0 {
+				files = append(files, loc)
+			}
+		}
+	}
+
+	return
+}
+
+// warnExpiring will log a warning for each certificate which expires within the warning period
+func (cs *certificateStore) warnExpiring(now time.Time) {
+	for _, cert := range *cs.certs.Load() {
+		remaining := cert.Leaf.NotAfter.Sub(now)
+		if remaining > certificateWarningPeriod {
+			continue
+		}
+
+		log.Printf("Vroomy: Warning, certificate for %s expires in %s (%s)",
+			getCertificateNames(cert), formatRemaining(remaining), cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+}
+
+// formatRemaining will format the time remaining before expiration in days, or as a duration when less than a day remains
+func formatRemaining(remaining time.Duration) string {
+	if days := int(remaining.Hours() / 24); days > 0 {
+		return fmt.Sprintf("%d days", days)
+	}
+
+	return remaining.Round(time.Minute).String()
+}
+
+// GetCertificate will return the first certificate which supports the client hello, or the first certificate
+func (cs *certificateStore) GetCertificate(chi *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certs := *cs.certs.Load()
+	for i := range certs {
+		if chi.SupportsCertificate(&certs[i]) == nil {
+			return &certs[i], nil
+		}
+	}
+
+	return &certs[0], nil
+}
+
+// validateCertificates will ensure certificates have been provided and are currently valid
+// Note: Leaves which have not been parsed (e.g. with GODEBUG=x509keypairleaf=0) are parsed and set
+func validateCertificates(certs []tls.Certificate, now time.Time) (err error) {
+	if len(certs) == 0 {
+		return ErrNoCertificates
+	}
+
+	for i := range certs {
+		if err = validateCertificate(&certs[i], now); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// validateCertificate will ensure a certificate is currently valid
+func validateCertificate(cert *tls.Certificate, now time.Time) (err error) {
+	if err = setLeaf(cert); err != nil {
+		return
+	}
+
+	switch {
+	case now.Before(cert.Leaf.NotBefore):
+		return fmt.Errorf("invalid certificate for %s, not valid until %s", getCertificateNames(*cert), cert.Leaf.NotBefore.Format(time.RFC3339))
+	case now.After(cert.Leaf.NotAfter):
+		return fmt.Errorf("invalid certificate for %s, expired %s", getCertificateNames(*cert), cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	return
+}
+
+// getValidCertificates will return the certificates which are currently valid, a warning is logged for each invalid certificate
+func getValidCertificates(certs []tls.Certificate, now time.Time) (valid []tls.Certificate) {
+	for i := range certs {
+		if err := validateCertificate(&certs[i], now); err != nil {
+			log.Printf("Vroomy: Warning, skipping certificate: %v", err)
+			continue
+		}
+
+		valid = append(valid, certs[i])
+	}
+
+	return
+}
+
+// setLeaf will parse and set the leaf of a certificate, when it has not been set
+func setLeaf(cert *tls.Certificate) (err error) {
+	if cert.Leaf != nil {
+		return
+	}
+
+	if len(cert.Certificate) == 0 {
+		return fmt.Errorf("invalid certificate, certificate chain cannot be empty")
+	}
+
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return fmt.Errorf("error parsing certificate: %v", err)
+	}
+
+	return
+}
+
+// getCertificateNames will return the DNS names of a certificate, falling back to its subject common name
+func getCertificateNames(cert tls.Certificate) string {
+	if len(cert.Leaf.DNSNames) == 0 {
+		return cert.Leaf.Subject.CommonName
+	}
+
+	return strings.Join(cert.Leaf.DNSNames, ", ")
+}
+
+// getCertificateStore will return the certificate store of the provided directory, loading it when it does not exist
+// Note: Listeners which share a TLS directory share a certificate store
+func (v *Vroomy) getCertificateStore(dir string) (cs *certificateStore, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if cs = v.certificates[dir]; cs != nil {
+		return
+	}
+
+	if cs, err = newCertificateStore(dir); err != nil {
+		return nil, fmt.Errorf("error loading certificates within <%s>: %v", dir, err)
+	}
+
+	if v.certificates == nil {
+		v.certificates = make(map[string]*certificateStore)
+	}
+
+	v.certificates[dir] = cs
+	return
+}
+
+func (v *Vroomy) getCertificateStores() (stores []*certificateStore) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, cs := range v.certificates {
+		stores = append(stores, cs)
+	}
+
+	return
+}
+
+// ReloadCertificates will reload the certificate pairs within the TLS directories, new handshakes use the reloaded certificates
+// Note: If any pair within a directory is invalid, the previous certificates of the directory continue to be served
+func (v *Vroomy) ReloadCertificates() (err error) {
+	var errs errors.ErrorList
+	for _, cs := range v.getCertificateStores() {
+		if err = cs.load(false); err != nil {
+			errs.Push(fmt.Errorf("error reloading certificates within <%s>: %v", cs.dir, err))
+		}
+	}
+
+	return errs.Err()
+}
+
+func (v *Vroomy) reloadCertificates() {
+	if err := v.ReloadCertificates(); err != nil {
+		log.Printf("Vroomy: %v, continuing to serve previous certificates", err)
+	}
+}
+
+// watchCertificates will reload certificates when the pairs within their TLS directory are modified
+// and periodically log certificates which are about to expire
+func (v *Vroomy) watchCertificates(ctx context.Context) {
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+	warnings := time.NewTicker(certificateWarningInterval)
+	defer warnings.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, cs := range v.getCertificateStores() {
+				if !cs.isModified() {
+					continue
+				}
+
+				if err := cs.load(false); err != nil {
+					log.Printf("Vroomy: Error reloading certificates within <%s>: %v, continuing to serve previous certificates", cs.dir, err)
+				}
+			}
+		case now := <-warnings.C:
+			for _, cs := range v.getCertificateStores() {
+				cs.warnExpiring(now)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package vroomy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_validateCertificates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCertificate(t, dir, "localhost")
+	certs, err := loadTLSCertificates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		certs       []tls.Certificate
+		now         time.Time
+		expectedErr string
+	}
+
+	begins := certs[0].Leaf.NotBefore
+	expires := certs[0].Leaf.NotAfter
+	tcs := []testcase{
+		{certs: certs, now: time.Now()},
+		{certs: nil, now: time.Now(), expectedErr: ErrNoCertificates.Error()},
+		{
+			certs:       certs,
+			now:         begins.Add(-time.Minute),
+			expectedErr: "invalid certificate for localhost, not valid until " + begins.Format(time.RFC3339),
+		},
+		{
+			certs:       certs,
+			now:         expires.Add(time.Minute),
+			expectedErr: "invalid certificate for localhost, expired " + expires.Format(time.RFC3339),
+		},
+		{
+			certs:       []tls.Certificate{{}},
+			now:         time.Now(),
+			expectedErr: "invalid certificate, certificate chain cannot be empty",
+		},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(validateCertificates(tc.certs, tc.now)); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func Test_validateCertificates_unparsedLeaf(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCertificate(t, dir, "localhost")
+	certs, err := loadTLSCertificates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Certificates are loaded without a leaf when GODEBUG=x509keypairleaf=0
+	certs[0].Leaf = nil
+	if err = validateCertificates(certs, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if certs[0].Leaf == nil {
+		t.Fatal("invalid leaf, expected leaf to be parsed and received nil")
+	}
+
+	if names := getCertificateNames(certs[0]); names != "localhost" {
+		t.Fatalf("invalid certificate names, expected <%s> and received <%s>", "localhost", names)
+	}
+}
+
+func Test_newCertificateStore_invalidPairs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCertificate(t, dir, "localhost")
+	writeTestCertificateWithValidity(t, dir, "expired", time.Now().Add(-time.Hour*2), time.Now().Add(-time.Hour))
+	writeTestCertificateWithValidity(t, dir, "pending", time.Now().Add(time.Hour), time.Now().Add(time.Hour*2))
+
+	// Invalid pairs are skipped when the certificates are initially loaded
+	cs, err := newCertificateStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs := *cs.certs.Load()
+	if len(certs) != 1 || certs[0].Leaf.Subject.CommonName != "localhost" {
+		t.Fatalf("invalid certificates, expected the certificate for <%s> and received %d certificates", "localhost", len(certs))
+	}
+
+	// Invalid pairs are rejected when the certificates are reloaded
+	if err = cs.load(false); err == nil {
+		t.Fatal("invalid error, expected error reloading invalid pairs")
+	}
+
+	if err = os.Remove(filepath.Join(dir, "localhost.crt")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.Remove(filepath.Join(dir, "localhost.key")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = newCertificateStore(dir); err != ErrNoCertificates {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrNoCertificates, err)
+	}
+}
+
+func Test_certificateStore_isModified(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCertificate(t, dir, "localhost")
+	cs, err := newCertificateStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cs.isModified() {
+		t.Fatal("invalid modified state, expected certificates to be unmodified after loading")
+	}
+
+	future := time.Now().Add(time.Minute)
+	if err = os.Chtimes(filepath.Join(dir, "localhost.crt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cs.isModified() {
+		t.Fatal("invalid modified state, expected certificates to be modified")
+	}
+
+	// Invalid pairs are only reported once per modification
+	if err = os.WriteFile(filepath.Join(dir, "other.crt"), []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = cs.load(false); err == nil {
+		t.Fatal("invalid error, expected error loading invalid pair")
+	}
+
+	if cs.isModified() {
+		t.Fatal("invalid modified state, expected failed load to be recorded")
+	}
+}
+
+func TestVroomy_ReloadCertificates(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeTestCertificate(t, dir, "localhost")
+
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.TLSListen = "127.0.0.1:0"
+	cfg.TLSDir = dir
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+	awaitReady(t, v)
+
+	url := "https://" + v.Addrs()[ListenerHTTPS].String() + "/hello"
+	first := getPeerCertificate(t, url)
+
+	// Rotate the certificate pair
+	writeTestCertificate(t, dir, "localhost")
+	if err = v.ReloadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := getPeerCertificate(t, url)
+	if first.SerialNumber.Cmp(second.SerialNumber) == 0 {
+		t.Fatalf("invalid certificate, expected rotated certificate and received serial %v", second.SerialNumber)
+	}
+
+	// Invalid pairs are rejected and the previous certificates continue to be served
+	if err = os.WriteFile(filepath.Join(dir, "localhost.key"), []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = v.ReloadCertificates(); err == nil {
+		t.Fatal("invalid error, expected error reloading invalid pair")
+	}
+
+	if serial := getPeerCertificate(t, url).SerialNumber; serial.Cmp(second.SerialNumber) != 0 {
+		t.Fatalf("invalid certificate, expected serial %v and received %v", second.SerialNumber, serial)
+	}
+}
+
+func getPeerCertificate(t *testing.T, url string) (cert *x509.Certificate) {
+	client := http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0]
+}
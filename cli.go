@@ -0,0 +1,121 @@
+package vroomy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Run will run the vroomy command line interface using the provided arguments
+// Supported commands are:
+//   - (none) will initialize the service and listen until a closing signal is received
+//   - test will run the self-tests for all registered plugins
+//   - graph [-format dot|mermaid|json] will print the plugin dependency graph
+//
+// Example usage within a main package:
+//
+//	if err := vroomy.Run(context.Background(), os.Args[1:]); err != nil {
+//		log.Fatal(err)
+//	}
+func Run(ctx context.Context, args []string) (err error) {
+	var (
+		configLocation string
+		dataDir        string
+	)
+
+	fs := flag.NewFlagSet("vroomy", flag.ContinueOnError)
+	fs.StringVar(&configLocation, "config", "", "location of the configuration file, use - to read from stdin (defaults to $CONFIG_PATH/config.toml)")
+	fs.StringVar(&dataDir, "dataDir", "", "initializes backends in provided directory, overrides value set in config")
+	fs.StringVar(&dataDir, "d", "", "shorthand for -dataDir")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if configLocation == "" {
+		var c Config
+		configLocation = c.GetFilepath()
+	}
+
+	switch cmd := fs.Arg(0); cmd {
+	case "":
+		return runServeCommand(ctx, configLocation, dataDir)
+	case "test":
+		return runTestCommand(configLocation)
+	case "graph":
+		return runGraphCommand(fs.Args()[1:])
+
+	default:
+		return fmt.Errorf("invalid command, <%s> is not supported", cmd)
+	}
+}
+
+func loadConfig(configLocation string) (cfg *Config, err error) {
+	if configLocation == "-" {
+		return NewConfigFromReader(os.Stdin, ConfigFormatTOML)
+	}
+
+	return NewConfig(configLocation)
+}
+
+func runServeCommand(ctx context.Context, configLocation, dataDir string) (err error) {
+	var cfg *Config
+	if cfg, err = loadConfig(configLocation); err != nil {
+		return
+	}
+
+	if len(dataDir) > 0 {
+		cfg.setEnvOverride("dataDir", dataDir)
+	}
+
+	cfg.setDefaultDataDir()
+
+	var v *Vroomy
+	if v, err = NewWithConfig(cfg); err != nil {
+		return
+	}
+
+	return v.ListenUntilSignal(ctx)
+}
+
+func runTestCommand(configLocation string) (err error) {
+	var env Environment
+	cfg, err := loadConfig(configLocation)
+	switch {
+	case err == nil:
+		env = cfg.Environment
+	case os.IsNotExist(err):
+		// Testing does not require a configuration file
+	default:
+		return
+	}
+
+	var results TestResults
+	if results, err = Test(env); err != nil {
+		return
+	}
+
+	fmt.Println(results.String())
+	return results.Err()
+}
+
+func runGraphCommand(args []string) (err error) {
+	var format string
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	fs.StringVar(&format, "format", GraphFormatDOT, "output format of the graph (dot, mermaid or json)")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	// Note: The graph is printed even when a circular import is encountered, plugins are not initialized
+	// so dependencies resolved by type are omitted and listed within the output
+	g, gerr := defaultRegistry.DependencyGraph()
+
+	var out string
+	if out, err = g.Format(format); err != nil {
+		return
+	}
+
+	fmt.Println(out)
+	return gerr
+}
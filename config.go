@@ -3,10 +3,14 @@ package vroomy
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
+	"net"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gdbu/errors"
@@ -26,35 +30,82 @@ const (
 
 // NewConfig will return a new configuration
 func NewConfig(loc string) (cfg *Config, err error) {
+	// Configuration location is made absolute so the configuration can be reloaded after a change of directory
+	if loc, err = filepath.Abs(loc); err != nil {
+		return
+	}
+
 	var c Config
 	if _, err = toml.DecodeFile(loc, &c); err != nil {
 		return
 	}
 
-	if err = c.loadIncludes(); err != nil {
+	c.sources = append(c.sources, loc)
+	if err = c.loadIncludes(osConfigFS{}, c.resolveIncludePath); err != nil {
 		return
 	}
 
-	if c.Dir == "" {
-		c.Dir = "./"
+	c.load = func() (*Config, error) { return NewConfig(loc) }
+	return c.init()
+}
+
+// NewConfigFromFS will return a new configuration read from the provided file system
+// Include paths are resolved within the same file system, relative to the directory of the configuration file
+func NewConfigFromFS(fsys fs.FS, name string) (cfg *Config, err error) {
+	var bs []byte
+	if bs, err = fs.ReadFile(fsys, name); err != nil {
+		return
 	}
 
-	if c.Environment == nil {
-		c.Environment = make(map[string]string)
+	var c Config
+	if _, err = toml.Decode(string(bs), &c); err != nil {
+		return
 	}
 
-	c.populateFromOSEnv()
-	cfg = &c
-	return
+	base := path.Dir(name)
+	if err = c.loadIncludes(fsConfigFS{fsys: fsys}, func(include string) string {
+		return path.Join(base, include)
+	}); err != nil {
+		return
+	}
+
+	// Sources are only watched for the operating system file system
+	c.sources = nil
+	c.load = func() (*Config, error) { return NewConfigFromFS(fsys, name) }
+	return c.init()
+}
+
+// NewConfigFromReader will return a new configuration read from the provided reader
+// Note: toml is currently the only supported format, include paths are resolved using the file system
+func NewConfigFromReader(r io.Reader, format string) (cfg *Config, err error) {
+	if err = validateConfigFormat(format); err != nil {
+		return
+	}
+
+	var c Config
+	if _, err = toml.NewDecoder(r).Decode(&c); err != nil {
+		return
+	}
+
+	if err = c.loadIncludes(osConfigFS{}, c.resolveIncludePath); err != nil {
+		return
+	}
+
+	return c.init()
 }
 
 // Config is the configuration needed to initialize a new instance of Service
 type Config struct {
 	Name string `toml:"name"`
 
+	// Dir is the base directory used to resolve relative paths (data, TLS, autocert, includes and route targets)
 	Dir  string `toml:"dir"`
 	Port uint16 `toml:"port"`
 
+	// ChangeDir will change the working directory of the process to Dir rather than resolving relative paths
+	// Note: This is the legacy behavior and prevents running multiple services (or parallel tests) within a process
+	ChangeDir bool `toml:"changeDir"`
+
 	// TLSPort to listen on. To use TLS one of the two must be set:
 	//	- TLSDir
 	//	- AutoCertHosts/AutoCertDir
@@ -63,6 +114,22 @@ type Config struct {
 	TLSDir      string `toml:"tlsDir"`
 	AllowNonTLS bool   `toml:"allowNonTLS"`
 
+	// Listen is the address to serve HTTP on, it takes precedence over Port when set
+	// Addresses are either TCP ("127.0.0.1:8080") or Unix domain sockets ("unix:///run/app.sock")
+	Listen string `toml:"listen"`
+	// TLSListen is the address to serve HTTPS on, it takes precedence over TLSPort when set
+	TLSListen string `toml:"tlsListen"`
+	// SocketMode is the octal file mode of Unix domain sockets (e.g. "0660")
+	SocketMode string `toml:"socketMode"`
+	// SocketOwner is the owner of Unix domain sockets ("user", "user:group" or ":group")
+	SocketOwner string `toml:"socketOwner"`
+
+	// Listeners are additional addresses to serve on, each optionally with its own TLS settings
+	Listeners []*Listener `toml:"listener"`
+
+	// Admin is an optional listener which serves its own routes and groups on a separate address
+	Admin *AdminConfig `toml:"admin"`
+
 	IncludeConfig
 
 	Flags map[string]string `toml:"-"`
@@ -70,7 +137,43 @@ type Config struct {
 	// Plugins to import
 	Plugins []string `toml:"plugins"`
 
+	// ReadTimeout is the maximum duration for reading an entire request, including the body (defaults to 5 minutes)
+	ReadTimeout time.Duration `toml:"readTimeout"`
+	// ReadHeaderTimeout is the maximum duration for reading request headers (defaults to the read timeout)
+	ReadHeaderTimeout time.Duration `toml:"readHeaderTimeout"`
+	// WriteTimeout is the maximum duration before timing out writes of the response (defaults to 5 minutes)
+	WriteTimeout time.Duration `toml:"writeTimeout"`
+	// IdleTimeout is the maximum duration to wait for the next request on a keep-alive connection (defaults to the read timeout)
+	IdleTimeout time.Duration `toml:"idleTimeout"`
+	// MaxHeaderBytes is the maximum size of request headers (defaults to 16384)
+	MaxHeaderBytes int `toml:"maxHeaderBytes"`
+	// MaxBodySize is the maximum size of request bodies (e.g. "10MB"), unlimited when unset
+	MaxBodySize ByteSize `toml:"maxBodySize"`
+
+	// TLS is the TLS configuration of the HTTPS listeners
+	TLS TLSConfig `toml:"tls"`
+
+	// HTTP2 is the HTTP/2 configuration of the listeners
+	HTTP2 HTTP2Config `toml:"http2"`
+
+	// ShutdownTimeout is the maximum time to wait for in-flight requests to complete during a graceful shutdown
+	// (e.g. "30s"), defaults to 30 seconds
+	ShutdownTimeout time.Duration `toml:"shutdownTimeout"`
+
+	// WatchConfig will reload routes and groups when the configuration file or its includes are modified
+	WatchConfig bool `toml:"watchConfig"`
+
 	ErrorLogger func(error) `toml:"-"`
+	// OnListen is called with the addresses of the listeners (by name) once all listeners are bound and accepting connections
+	OnListen func(addrs map[string]net.Addr) `toml:"-"`
+
+	// load will re-read the configuration from its source
+	load func() (*Config, error)
+	// overrides are environment values which were set programmatically rather than by the configuration source
+	// (e.g. the -dataDir flag), they are applied to reloaded configurations
+	overrides map[string]string
+	// sources are the configuration files and include directories which were read
+	sources []string
 }
 
 func (c *Config) GetFilepath() (filepath string) {
@@ -83,6 +186,69 @@ func (c *Config) GetFilepath() (filepath string) {
 	return path.Join(dir, "config.toml")
 }
 
+// resolvePath will resolve a relative path against the configuration directory
+func (c *Config) resolvePath(loc string) string {
+	if len(loc) == 0 || filepath.IsAbs(loc) {
+		return loc
+	}
+
+	return filepath.Join(c.Dir, loc)
+}
+
+// resolvePaths will resolve all relative paths of the configuration against the configuration directory
+func (c *Config) resolvePaths() (err error) {
+	if c.Dir, err = filepath.Abs(c.Dir); err != nil {
+		return
+	}
+
+	if dataDir, ok := c.Environment["dataDir"]; ok {
+		c.Environment["dataDir"] = c.resolvePath(dataDir)
+	}
+
+	c.TLSDir = c.resolvePath(c.TLSDir)
+	c.AutoCertDir = c.resolvePath(c.AutoCertDir)
+	c.TLS.ClientCA = c.resolvePath(c.TLS.ClientCA)
+	c.Listen = c.resolveListenAddress(c.Listen)
+	c.TLSListen = c.resolveListenAddress(c.TLSListen)
+	for _, l := range c.Listeners {
+		l.Address = c.resolveListenAddress(l.Address)
+		l.TLSDir = c.resolvePath(l.TLSDir)
+	}
+	for _, r := range c.Routes {
+		r.Target = c.resolvePath(r.Target)
+	}
+
+	if c.Admin != nil {
+		c.Admin.resolvePaths(c)
+	}
+
+	return
+}
+
+// setEnvOverride will set an environment value which is kept when the configuration is reloaded
+func (c *Config) setEnvOverride(key, value string) {
+	if c.overrides == nil {
+		c.overrides = make(map[string]string)
+	}
+
+	c.overrides[key] = value
+	c.Environment[key] = value
+}
+
+// applyEnvOverrides will set the provided environment overrides
+func (c *Config) applyEnvOverrides(overrides map[string]string) {
+	for key, value := range overrides {
+		c.setEnvOverride(key, value)
+	}
+}
+
+func (c *Config) setDefaultDataDir() {
+	if _, ok := c.Environment["dataDir"]; !ok {
+		// Default if not set elsewhere
+		c.Environment["dataDir"] = "data"
+	}
+}
+
 func (c *Config) hasTLSDir() (ok bool) {
 	return len(c.TLSDir) > 0
 }
@@ -99,10 +265,35 @@ func (c *Config) hasAutoCert() (ok bool) {
 	}
 }
 
-func (c *Config) loadIncludes() (err error) {
+func (c *Config) init() (cfg *Config, err error) {
+	if c.Dir == "" {
+		c.Dir = "./"
+	}
+
+	if c.Environment == nil {
+		c.Environment = make(map[string]string)
+	}
+
+	c.populateFromOSEnv()
+	c.setDefaultListenerNames()
+	cfg = c
+	return
+}
+
+func (c *Config) resolveIncludePath(include string) string {
+	if c.ChangeDir {
+		// Legacy behavior, include paths are relative to the working directory
+		return include
+	}
+
+	// Include paths are relative to the configuration directory
+	return c.resolvePath(include)
+}
+
+func (c *Config) loadIncludes(cfs configFS, resolve func(string) string) (err error) {
 	for _, include := range c.Include {
 		// Include each file or directory
-		if err = c.loadInclude(include); err != nil {
+		if err = c.loadInclude(cfs, resolve(include)); err != nil {
 			// Include failed
 			return
 		}
@@ -111,25 +302,35 @@ func (c *Config) loadIncludes() (err error) {
 	return
 }
 
-func (c *Config) loadInclude(include string) (err error) {
+func (c *Config) loadInclude(cfs configFS, include string) (err error) {
 	if path.Ext(include) == ".toml" {
 		// Attempt to decode toml
-		var icfg IncludeConfig
-		if _, err = toml.DecodeFile(include, &icfg); err != nil {
+		var bs []byte
+		if bs, err = cfs.ReadFile(include); err != nil {
 			return
 		}
 
+		c.sources = append(c.sources, include)
+
+		var icfg IncludeConfig
+		if _, err = toml.Decode(string(bs), &icfg); err != nil {
+			return fmt.Errorf("error decoding %s: %v", include, err)
+		}
+
 		c.IncludeConfig.merge(&icfg)
 	} else {
 		// Attempt to parse directory
 		var files []fs.DirEntry
-		if files, err = os.ReadDir(include); err != nil {
+		if files, err = cfs.ReadDir(include); err != nil {
 			return fmt.Errorf("%s is not a .toml file or directory", include)
 		}
 
+		// Directories are included so new files are picked up when watching
+		c.sources = append(c.sources, include)
+
 		// Call recursively
 		for _, file := range files {
-			if err = c.loadInclude(path.Join(include, file.Name())); err != nil {
+			if err = c.loadInclude(cfs, path.Join(include, file.Name())); err != nil {
 				return
 			}
 		}
@@ -138,6 +339,93 @@ func (c *Config) loadInclude(include string) (err error) {
 	return
 }
 
+// Validate will validate the settings, routes and groups of the configuration
+// Note: Routes and groups are validated more strictly than when a service is created from a configuration or a
+// Builder (e.g. unsupported methods are rejected rather than served as GET), Validate is only called when requested
+func (c *Config) Validate() (err error) {
+	var errs errors.ErrorList
+	errs.Push(c.validateSettings())
+	errs.Push(c.validateRoutes())
+	if c.Admin != nil {
+		errs.Push(c.Admin.validateRoutes())
+	}
+
+	return errs.Err()
+}
+
+// validateSettings will validate the listener, TLS, HTTP/2 and admin settings of the configuration
+func (c *Config) validateSettings() (err error) {
+	var errs errors.ErrorList
+	errs.Push(c.validateListeners())
+	errs.Push(c.TLS.validate(&c.HTTP2))
+	errs.Push(c.HTTP2.validate())
+	if c.Admin != nil {
+		errs.Push(c.Admin.validate())
+	}
+
+	return errs.Err()
+}
+
+// validateRoutes will validate the routes and groups of the configuration
+func (c *Config) validateRoutes() (err error) {
+	var errs errors.ErrorList
+	names := make(map[string]struct{}, len(c.Groups))
+	for _, g := range c.Groups {
+		if _, ok := names[g.Name]; ok {
+			errs.Push(fmt.Errorf("invalid group <%s>, name has already been used", g.Name))
+		}
+
+		names[g.Name] = struct{}{}
+		errs.Push(c.validateGroup(g))
+	}
+
+	for _, r := range c.Routes {
+		errs.Push(c.validateRoute(r))
+	}
+
+	return errs.Err()
+}
+
+func (c *Config) validateGroup(g *RouteGroup) (err error) {
+	if len(g.Name) == 0 {
+		return fmt.Errorf("invalid group with path of \"%s\", name cannot be empty", g.HTTPPath)
+	}
+
+	if g.Group == g.Name {
+		return fmt.Errorf("invalid group <%s>, cannot be nested within itself", g.Name)
+	}
+
+	if _, err = c.GetRouteGroup(g.Group); err != nil {
+		return fmt.Errorf("invalid group <%s>, parent group <%s>: %v", g.Name, g.Group, err)
+	}
+
+	if err = validateHandlers(g.Handlers); err != nil {
+		return fmt.Errorf("invalid group <%s>: %v", g.Name, err)
+	}
+
+	return
+}
+
+func (c *Config) validateRoute(r *Route) (err error) {
+	if len(r.HTTPPath) == 0 || r.HTTPPath[0] != '/' {
+		return fmt.Errorf("invalid route %s, HTTP path must begin with a forward slash", r.String())
+	}
+
+	if !isSupportedMethod(r.Method) {
+		return fmt.Errorf("invalid route %s, method of <%s> is not supported", r.String(), r.Method)
+	}
+
+	if _, err = c.GetRouteGroup(r.Group); err != nil {
+		return fmt.Errorf("invalid route %s, group <%s>: %v", r.String(), r.Group, err)
+	}
+
+	if err = validateHandlers(r.Handlers); err != nil {
+		return fmt.Errorf("invalid route %s: %v", r.String(), err)
+	}
+
+	return
+}
+
 // GetGroup will return group with name
 func (c *Config) GetRouteGroup(name string) (g *RouteGroup, err error) {
 	if len(name) == 0 {
@@ -158,19 +446,14 @@ func (c *Config) GetRouteGroup(name string) (g *RouteGroup, err error) {
 	return
 }
 
-func (c *Config) autoCertConfig() (ac httpserve.AutoCertConfig, err error) {
+func (c *Config) autoCertConfig(primary autocert.HostPolicy) (ac httpserve.AutoCertConfig) {
 	ac.DirCache = c.AutoCertDir
 	ac.Hosts = c.AutoCertHosts
-	ac.HostPolicy, err = c.getHostPolicy()
+	ac.HostPolicy = c.getHostPolicy(primary)
 	return
 }
 
-func (c *Config) getHostPolicy() (hp autocert.HostPolicy, err error) {
-	var primary autocert.HostPolicy
-	if primary, err = getHostPolicy(); err != nil {
-		return
-	}
-
+func (c *Config) getHostPolicy(primary autocert.HostPolicy) (hp autocert.HostPolicy) {
 	backup := autocert.HostWhitelist(c.AutoCertHosts...)
 	if primary == nil {
 		primary = backup
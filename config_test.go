@@ -0,0 +1,121 @@
+package vroomy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewConfig_resolvePaths(t *testing.T) {
+	dir := t.TempDir()
+	cfgStr := fmt.Sprintf(`
+dir = %q
+tlsDir = "tls"
+include = ["routes.toml"]
+
+[env]
+dataDir = "data"
+`, dir)
+
+	routesStr := `
+[[route]]
+httpPath = "/hello"
+handlers = ["a.Hello"]
+target = "public_html"
+`
+
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(cfgStr), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "routes.toml"), []byte(routesStr), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewConfig(filepath.Join(dir, "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry()
+	if err = r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewWithRegistry(cfg, r); err != nil {
+		t.Fatal(err)
+	}
+
+	if current, _ := os.Getwd(); current != wd {
+		t.Fatalf("invalid working directory, expected <%s> and received <%s>", wd, current)
+	}
+
+	dataDir := filepath.Join(dir, "data")
+	if _, err = os.Stat(dataDir); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case cfg.Environment["dataDir"] != dataDir:
+		t.Fatalf("invalid data directory, expected <%s> and received <%s>", dataDir, cfg.Environment["dataDir"])
+	case cfg.TLSDir != filepath.Join(dir, "tls"):
+		t.Fatalf("invalid TLS directory, expected <%s> and received <%s>", filepath.Join(dir, "tls"), cfg.TLSDir)
+	case cfg.Routes[0].Target != filepath.Join(dir, "public_html"):
+		t.Fatalf("invalid target, expected <%s> and received <%s>", filepath.Join(dir, "public_html"), cfg.Routes[0].Target)
+	}
+}
+
+func TestNewConfigFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/config.toml": &fstest.MapFile{Data: []byte(`
+port = 8080
+include = ["routes"]
+`)},
+		"config/routes/hello.toml": &fstest.MapFile{Data: []byte(`
+[[route]]
+httpPath = "/hello"
+handlers = ["a.Hello"]
+`)},
+	}
+
+	cfg, err := NewConfigFromFS(fsys, "config/config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Fatalf("invalid port, expected %d and received %d", 8080, cfg.Port)
+	}
+
+	if len(cfg.Routes) != 1 || cfg.Routes[0].HTTPPath != "/hello" {
+		t.Fatalf("invalid routes, received %v", cfg.Routes)
+	}
+}
+
+func TestNewConfigFromReader(t *testing.T) {
+	cfg, err := NewConfigFromReader(strings.NewReader("port = 8080"), ConfigFormatTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Fatalf("invalid port, expected %d and received %d", 8080, cfg.Port)
+	}
+
+	if cfg.Dir != "./" || cfg.Environment == nil {
+		t.Fatalf("invalid defaults, received dir of <%s> and environment of %v", cfg.Dir, cfg.Environment)
+	}
+
+	want := "invalid config format, <yaml> is not supported"
+	if _, err = NewConfigFromReader(strings.NewReader("port = 8080"), "yaml"); err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+}
@@ -0,0 +1,51 @@
+package vroomy
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// ConfigFormatTOML is the TOML configuration format
+const ConfigFormatTOML = "toml"
+
+// configFS is used to read configuration includes
+type configFS interface {
+	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+}
+
+// osConfigFS reads configuration includes from the operating system file system
+type osConfigFS struct{}
+
+func (osConfigFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osConfigFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// fsConfigFS reads configuration includes from an fs.FS
+type fsConfigFS struct {
+	fsys fs.FS
+}
+
+func (f fsConfigFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(f.fsys, name)
+}
+
+func (f fsConfigFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(f.fsys, name)
+}
+
+func validateConfigFormat(format string) (err error) {
+	switch strings.ToLower(format) {
+	case "", ConfigFormatTOML:
+		return
+
+	default:
+		return fmt.Errorf("invalid config format, <%s> is not supported", format)
+	}
+}
@@ -3,12 +3,18 @@ package vroomy
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 
 	"github.com/gdbu/errors"
 	"github.com/gdbu/stringset"
 )
 
 func makeDependencyMap(val interface{}) (m dependencyMap) {
+	return makeDependencyMapWithResolver(val, nil)
+}
+
+func makeDependencyMapWithResolver(val interface{}, r *typeResolver) (m dependencyMap) {
 	rtype := reflect.TypeOf(val)
 	if rtype.Kind() == reflect.Ptr {
 		rtype = rtype.Elem()
@@ -16,43 +22,92 @@ func makeDependencyMap(val interface{}) (m dependencyMap) {
 
 	numFields := rtype.NumField()
 	m = make(dependencyMap, numFields)
-	appendDependencyMap(m, nil, rtype)
+	appendDependencyMap(m, nil, "", rtype, r)
 	return
 }
 
-func appendDependencyMap(m dependencyMap, prefix []int, rtype reflect.Type) {
+func appendDependencyMap(m dependencyMap, prefix []int, path string, rtype reflect.Type, r *typeResolver) {
 	if rtype.Kind() == reflect.Pointer {
 		rtype = rtype.Elem()
 	}
 
+	autoWire := hasAutoWire(rtype)
 	numFields := rtype.NumField()
 	for i := 0; i < numFields; i++ {
 		field := rtype.Field(i)
-		fieldValue := field.Tag.Get("vroomy")
+		fieldPath := getFieldPath(path, field)
+		fieldValue, hasTag := field.Tag.Lookup("vroomy")
 		switch {
 		case field.Anonymous:
 			copied := copySlice(prefix)
 			copied = append(copied, i)
-			appendDependencyMap(m, copied, field.Type)
+			appendDependencyMap(m, copied, fieldPath, field.Type, r)
+		case !hasTag && autoWire && isAutoWireable(field):
+			copied := copySlice(prefix)
+			m[fieldPath] = newAutoDependency(append(copied, i), field, r)
 		case fieldValue == "":
 			continue
 		default:
 			copied := copySlice(prefix)
-			m[fieldValue] = append(copied, i)
+			dep := newDependency(fieldValue, append(copied, i), field)
+			switch {
+			case dep.key == dependencyKeyCollection:
+				dep = dep.resolveCollection(r)
+			case dep.auto && len(dep.key) == 0:
+				dep = dep.resolve(r)
+			}
+
+			m[fieldPath] = dep
 		}
 
 	}
 }
 
+// getFieldPath will return the dot-separated path of a field (e.g. "Embedded.Users")
+func getFieldPath(path string, field reflect.StructField) string {
+	if len(path) == 0 {
+		return field.Name
+	}
+
+	return path + "." + field.Name
+}
+
+// makeDependenciesMap will create the dependencies map of the provided plugins
+// Note: Backends are only called when a dependency is resolved by type
 func makeDependenciesMap(ps map[string]Plugin) (dm dependenciesMap) {
+	var backends map[string]interface{}
+	if hasTypedDependencies(ps) {
+		backends = make(map[string]interface{}, len(ps))
+		for key, pi := range ps {
+			backends[key] = pi.Backend()
+		}
+	}
+
+	return makeDependenciesMapWithBackends(ps, backends)
+}
+
+func makeDependenciesMapWithBackends(ps map[string]Plugin, backends map[string]interface{}) (dm dependenciesMap) {
+	r := newTypeResolver(backends)
 	dm = make(dependenciesMap, len(ps))
 	for key, p := range ps {
-		dm[key] = makeDependencyMap(p)
+		dm[key] = makeDependencyMapWithResolver(p, r.Without(key))
 	}
 
 	return
 }
 
+// hasTypedDependencies will return whether or not any of the plugins have a dependency which is resolved by type
+// (tagged with "auto" and no key, tagged with "*" or auto wired)
+func hasTypedDependencies(ps map[string]Plugin) bool {
+	for _, p := range ps {
+		if makeDependencyMap(p).hasTyped() {
+			return true
+		}
+	}
+
+	return false
+}
+
 type dependenciesMap map[string]dependencyMap
 
 func (d dependenciesMap) Validate() (err error) {
@@ -93,15 +148,17 @@ func (d dependenciesMap) Load(fn func(pluginKey string, dm dependencyMap) error)
 		return
 	}
 
+	keys := d.keys()
 	loaded := make(stringset.Map, len(d))
 	for len(loaded) < len(d) {
 		var passCount int
-		for key, dm := range d {
+		for _, key := range keys {
+			dm := d[key]
 			if loaded.Has(key) {
 				continue
 			}
 
-			if !dm.isReady(loaded) {
+			if !d.isReady(dm, loaded) {
 				continue
 			}
 
@@ -114,8 +171,7 @@ func (d dependenciesMap) Load(fn func(pluginKey string, dm dependencyMap) error)
 		}
 
 		if passCount == 0 {
-			remaining := d.getRemaining(loaded)
-			err = fmt.Errorf("circular import error, affected plugins: %v", remaining)
+			err = &CycleError{Path: d.getCycle(loaded)}
 			return
 		}
 	}
@@ -123,32 +179,227 @@ func (d dependenciesMap) Load(fn func(pluginKey string, dm dependencyMap) error)
 	return
 }
 
+func (d dependenciesMap) loadOrder() (order []string, err error) {
+	order = make([]string, 0, len(d))
+	if err = d.Load(func(pluginKey string, _ dependencyMap) error {
+		order = append(order, pluginKey)
+		return nil
+	}); err != nil {
+		order = nil
+		return
+	}
+
+	return
+}
+
+// getCycle will return a circular import path among the plugins which could not be loaded
+func (d dependenciesMap) getCycle(loaded stringset.Map) (path []string) {
+	remaining := d.getRemaining(loaded)
+	sort.Strings(remaining)
+
+	// Each remaining plugin has at least one dependency which has not been loaded,
+	// following the first of those dependencies will eventually revisit a plugin
+	visited := make(map[string]int, len(remaining))
+	key := remaining[0]
+	for {
+		if index, ok := visited[key]; ok {
+			path = append(path[index:], key)
+			return
+		}
+
+		visited[key] = len(path)
+		path = append(path, key)
+		key = d.getFirstPending(d[key], loaded)
+	}
+}
+
+func (d dependenciesMap) getFirstPending(dm dependencyMap, loaded stringset.Map) (pending string) {
+	for _, fieldPath := range dm.keys() {
+		if pending, ok := d.getPending(dm[fieldPath], loaded); ok {
+			return pending
+		}
+	}
+
+	return ""
+}
+
+func (d dependenciesMap) isReady(dm dependencyMap, loaded stringset.Map) (isReady bool) {
+	for _, dep := range dm {
+		if _, ok := d.getPending(dep, loaded); ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getPending returns the key of a plugin which must be loaded before the dependency and has not yet been loaded
+func (d dependenciesMap) getPending(dep dependency, loaded stringset.Map) (pending string, ok bool) {
+	if !dep.isOrdered() {
+		return
+	}
+
+	for _, pluginKey := range dep.getPluginKeys() {
+		if _, ok := d[pluginKey]; !ok {
+			// Dependency is not registered, which is only valid for optional dependencies
+			continue
+		}
+
+		if !loaded.Has(pluginKey) {
+			return pluginKey, true
+		}
+	}
+
+	return
+}
+
+func (d dependenciesMap) keys() (keys []string) {
+	keys = make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return
+}
+
 func (d dependenciesMap) validateDependency(key string, dm dependencyMap) (err error) {
-	if _, ok := dm[key]; ok {
+	if dm.hasKey(key) {
 		return fmt.Errorf("self import error: <%s> cannot import itself", key)
 	}
 
+	for _, fieldPath := range dm.keys() {
+		dep := dm[fieldPath]
+		if err = dep.validate(); err != nil {
+			return fmt.Errorf("error validating dependencies of <%s>: %v", key, err)
+		}
+	}
+
+	return
+}
+
+// withoutTyped will return a copy of the dependencies map without the dependencies which are resolved by type,
+// along with the sorted paths (plugin key and field path) of the omitted dependencies
+func (d dependenciesMap) withoutTyped() (out dependenciesMap, omitted []string) {
+	out = make(dependenciesMap, len(d))
+	for _, key := range d.keys() {
+		dm := d[key]
+		out[key] = make(dependencyMap, len(dm))
+		for _, fieldPath := range dm.keys() {
+			dep := dm[fieldPath]
+			if dep.typed {
+				omitted = append(omitted, key+"."+fieldPath)
+				continue
+			}
+
+			out[key][fieldPath] = dep
+		}
+	}
+
 	return
 }
 
-type dependencyMap map[string][]int
+// hasTyped will return whether or not any of the plugins have a dependency which is resolved by type
+func (d dependenciesMap) hasTyped() bool {
+	for _, dm := range d {
+		if dm.hasTyped() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateBackend will ensure the backend of a loaded plugin would not have been resolved by type by any other plugin
+// Note: Type resolution occurs before plugins are loaded, backends set during Load cannot be resolved by type
+func (d dependenciesMap) validateBackend(pluginKey string, backend interface{}) (err error) {
+	if backend == nil {
+		return
+	}
+
+	backendType := reflect.TypeOf(backend)
+	for _, key := range d.keys() {
+		if key == pluginKey {
+			continue
+		}
+
+		dm := d[key]
+		for _, fieldPath := range dm.keys() {
+			dep := dm[fieldPath]
+			if !dep.isTyped(backendType) || slices.Contains(dep.getPluginKeys(), pluginKey) {
+				continue
+			}
+
+			return fmt.Errorf("backend of <%s> matches field <%s> of <%s> by type, but was not set before the plugins were loaded (backends resolved by type must be set when the plugin is initialized)",
+				pluginKey, fieldPath, key)
+		}
+	}
+
+	return
+}
+
+// dependencyMap is the dependencies of a plugin, keyed by field path
+type dependencyMap map[string]dependency
 
 func (d dependencyMap) validateRegistration(dm dependenciesMap) (err error) {
-	for key := range d {
-		if _, ok := dm[key]; !ok {
-			return fmt.Errorf("dependency with key of <%s> not found in dependencies map", key)
+	for _, fieldPath := range d.keys() {
+		dep := d[fieldPath]
+		if dep.err != nil {
+			return dep.err
 		}
+
+		if _, ok := dm[dep.key]; ok || dep.optional || dep.placeholder || dep.reserved {
+			continue
+		}
+
+		return fmt.Errorf("dependency with key of <%s> not found in dependencies map", dep.key)
 	}
 
 	return
 }
 
-func (d dependencyMap) isReady(loaded stringset.Map) (isReady bool) {
+// hasTyped will return whether or not any of the dependencies are resolved by type
+func (d dependencyMap) hasTyped() bool {
+	for _, dep := range d {
+		if dep.typed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasKey will return whether or not any of the dependencies reference the provided key
+func (d dependencyMap) hasKey(key string) bool {
+	for _, dep := range d {
+		if dep.key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d dependencyMap) keys() (keys []string) {
+	keys = make([]string, 0, len(d))
 	for key := range d {
-		if !loaded.Has(key) {
-			return false
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+// pluginKeys will return the sorted, unique plugin keys referenced by the dependencies
+func (d dependencyMap) pluginKeys() (keys []string) {
+	set := make(stringset.Map, len(d))
+	for _, dep := range d {
+		for _, pluginKey := range dep.getPluginKeys() {
+			set.Set(pluginKey)
 		}
 	}
 
-	return true
+	keys = set.Slice()
+	sort.Strings(keys)
+	return
 }
@@ -156,7 +156,7 @@ func Test_makeDependenciesMap(t *testing.T) {
 
 	type testcase struct {
 		val     map[string]Plugin
-		want    dependenciesMap
+		want    map[string]map[string][]int
 		wantErr error
 	}
 
@@ -178,8 +178,8 @@ func Test_makeDependenciesMap(t *testing.T) {
 			val: map[string]Plugin{
 				"a": &a,
 			},
-			want: dependenciesMap{
-				"a": dependencyMap{},
+			want: map[string]map[string][]int{
+				"a": map[string][]int{},
 			},
 			wantErr: nil,
 		},
@@ -188,9 +188,9 @@ func Test_makeDependenciesMap(t *testing.T) {
 				"a": &a,
 				"b": &b,
 			},
-			want: dependenciesMap{
-				"a": dependencyMap{},
-				"b": dependencyMap{
+			want: map[string]map[string][]int{
+				"a": map[string][]int{},
+				"b": map[string][]int{
 					"a": []int{1},
 				},
 			},
@@ -202,12 +202,12 @@ func Test_makeDependenciesMap(t *testing.T) {
 				"b": &b,
 				"c": &c,
 			},
-			want: dependenciesMap{
-				"a": dependencyMap{},
-				"b": dependencyMap{
+			want: map[string]map[string][]int{
+				"a": map[string][]int{},
+				"b": map[string][]int{
 					"a": []int{1},
 				},
-				"c": dependencyMap{
+				"c": map[string][]int{
 					"a": []int{1},
 					"b": []int{2},
 				},
@@ -222,19 +222,19 @@ func Test_makeDependenciesMap(t *testing.T) {
 				"d": &d,
 				"e": &e,
 			},
-			want: dependenciesMap{
-				"a": dependencyMap{},
-				"b": dependencyMap{
+			want: map[string]map[string][]int{
+				"a": map[string][]int{},
+				"b": map[string][]int{
 					"a": []int{1},
 				},
-				"c": dependencyMap{
+				"c": map[string][]int{
 					"a": []int{1},
 					"b": []int{2},
 				},
-				"d": dependencyMap{
+				"d": map[string][]int{
 					"a": []int{1},
 				},
-				"e": dependencyMap{
+				"e": map[string][]int{
 					"c": []int{1},
 				},
 			},
@@ -249,22 +249,22 @@ func Test_makeDependenciesMap(t *testing.T) {
 				"e": &e,
 				"f": &f,
 			},
-			want: dependenciesMap{
-				"a": dependencyMap{},
-				"b": dependencyMap{
+			want: map[string]map[string][]int{
+				"a": map[string][]int{},
+				"b": map[string][]int{
 					"a": []int{1},
 				},
-				"c": dependencyMap{
+				"c": map[string][]int{
 					"a": []int{1},
 					"b": []int{2},
 				},
-				"d": dependencyMap{
+				"d": map[string][]int{
 					"a": []int{1},
 				},
-				"e": dependencyMap{
+				"e": map[string][]int{
 					"c": []int{1},
 				},
-				"f": dependencyMap{
+				"f": map[string][]int{
 					"g": []int{1},
 				},
 			},
@@ -280,32 +280,29 @@ func Test_makeDependenciesMap(t *testing.T) {
 				"f": &f,
 				"g": &g,
 			},
-			want: dependenciesMap{
-				"a": dependencyMap{},
-				"b": dependencyMap{
+			want: map[string]map[string][]int{
+				"a": map[string][]int{},
+				"b": map[string][]int{
 					"a": []int{1},
 				},
-				"c": dependencyMap{
+				"c": map[string][]int{
 					"a": []int{1},
 					"b": []int{2},
 				},
-				"d": dependencyMap{
+				"d": map[string][]int{
 					"a": []int{1},
 				},
-				"e": dependencyMap{
+				"e": map[string][]int{
 					"c": []int{1},
 				},
-				"f": dependencyMap{
+				"f": map[string][]int{
 					"g": []int{1},
 				},
-				"g": dependencyMap{
+				"g": map[string][]int{
 					"f": []int{1},
 				},
 			},
-			wantErr: makeErrorsList(
-				"circular import error: plugin of <g> imports <f>",
-				"circular import error: plugin of <f> imports <g>",
-			),
+			wantErr: errors.Error("circular import error: f -> g -> f"),
 		},
 		{
 			val: map[string]Plugin{
@@ -313,22 +310,18 @@ func Test_makeDependenciesMap(t *testing.T) {
 				"i": &i,
 				"j": &j,
 			},
-			want: dependenciesMap{
-				"h": dependencyMap{
+			want: map[string]map[string][]int{
+				"h": map[string][]int{
 					"j": []int{1},
 				},
-				"i": dependencyMap{
+				"i": map[string][]int{
 					"h": []int{1},
 				},
-				"j": dependencyMap{
+				"j": map[string][]int{
 					"i": []int{1},
 				},
 			},
-			wantErr: makeErrorsList(
-				"dependency of <h> failed with: circular import error: plugin of <i> imports <h>",
-				"dependency of <i> failed with: circular import error: plugin of <j> imports <i>",
-				"dependency of <j> failed with: circular import error: plugin of <h> imports <j>",
-			),
+			wantErr: errors.Error("circular import error: h -> j -> i -> h"),
 		},
 	}
 
@@ -345,6 +338,223 @@ func Test_makeDependenciesMap(t *testing.T) {
 	}
 }
 
+func Test_dependenciesMap_options(t *testing.T) {
+	type A struct {
+		BasePlugin
+
+		Cache Plugin       `vroomy:"cache,optional"`
+		B     Lazy[Plugin] `vroomy:"b,lazy"`
+	}
+
+	type B struct {
+		BasePlugin
+
+		A Plugin `vroomy:"a"`
+	}
+
+	type C struct {
+		BasePlugin
+
+		A Plugin `vroomy:"a,eager"`
+	}
+
+	type D struct {
+		BasePlugin
+
+		A Plugin `vroomy:"a,lazy"`
+	}
+
+	type testcase struct {
+		val           map[string]Plugin
+		wantLoadOrder []string
+		wantErr       error
+	}
+
+	tcs := []testcase{
+		{
+			val: map[string]Plugin{
+				"a": &A{},
+				"b": &B{},
+			},
+			wantLoadOrder: []string{"a", "b"},
+		},
+		{
+			val: map[string]Plugin{
+				"a": &A{},
+				"b": &B{},
+				"c": &C{},
+			},
+			wantErr: errors.Error("error validating dependencies of <c>: invalid vroomy tag options for <a>: [eager]"),
+		},
+		{
+			val: map[string]Plugin{
+				"a": &A{},
+				"b": &B{},
+				"d": &D{},
+			},
+			wantErr: errors.Error("error validating dependencies of <d>: invalid field type for lazy dependency <a>, expected vroomy.Lazy and received vroomy.Plugin"),
+		},
+	}
+
+	for i, tc := range tcs {
+		m := makeDependenciesMap(tc.val)
+		err := m.Validate()
+		if !errorEqual(tc.wantErr, err) {
+			t.Fatalf("invalid error, expected <%v> and received <%v> (test case index #%d)", tc.wantErr, err, i)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		order, err := m.loadOrder()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !stringSliceEqual(tc.wantLoadOrder, order) {
+			t.Fatalf("invalid load order, expected %v and received %v (test case index #%d)", tc.wantLoadOrder, order, i)
+		}
+	}
+}
+
+type testGreeter interface {
+	Greet() string
+}
+
+type greeterPlugin struct {
+	BasePlugin
+}
+
+func (g *greeterPlugin) Greet() string {
+	return "hello"
+}
+
+func (g *greeterPlugin) Backend() interface{} {
+	return g
+}
+
+func Test_dependenciesMap_auto(t *testing.T) {
+	type A struct {
+		BasePlugin
+
+		Greeter testGreeter `vroomy:",auto"`
+	}
+
+	type B struct {
+		BasePlugin
+		AutoWire
+
+		Greeter testGreeter
+	}
+
+	type C struct {
+		BasePlugin
+
+		Greeter testGreeter `vroomy:",auto,optional"`
+	}
+
+	type testcase struct {
+		val     map[string]Plugin
+		want    map[string]map[string][]int
+		wantErr error
+	}
+
+	tcs := []testcase{
+		{
+			val: map[string]Plugin{
+				"a":       &A{},
+				"b":       &B{},
+				"greeter": &greeterPlugin{},
+			},
+			want: map[string]map[string][]int{
+				"a":       {"greeter": {1}},
+				"b":       {"greeter": {2}},
+				"greeter": {},
+			},
+		},
+		{
+			val: map[string]Plugin{
+				"a": &A{},
+				"c": &C{},
+			},
+			want: map[string]map[string][]int{
+				"a": {"<auto Greeter>": {1}},
+				"c": {"<auto Greeter>": {1}},
+			},
+			wantErr: errors.Error("error resolving field <Greeter> by type: no plugin backend matches type vroomy.testGreeter"),
+		},
+		{
+			val: map[string]Plugin{
+				"a":  &A{},
+				"g1": &greeterPlugin{},
+				"g2": &greeterPlugin{},
+			},
+			want: map[string]map[string][]int{
+				"a":  {"<auto Greeter>": {1}},
+				"g1": {},
+				"g2": {},
+			},
+			wantErr: errors.Error("error resolving field <Greeter> by type: ambiguous type vroomy.testGreeter, matched by plugins [g1 g2]"),
+		},
+	}
+
+	for i, tc := range tcs {
+		m := makeDependenciesMap(tc.val)
+		if !dependenciesMapEqual(tc.want, m) {
+			t.Fatalf("invalid value, expected <%v> and received <%v> (test case index #%d)", tc.want, m, i)
+		}
+
+		if err := m.Validate(); !errorEqual(tc.wantErr, err) {
+			t.Fatalf("invalid error, expected <%v> and received <%v> (test case index #%d)", tc.wantErr, err, i)
+		}
+	}
+}
+
+func Test_dependenciesMap_multipleFields(t *testing.T) {
+	type A struct {
+		BasePlugin
+
+		Greeter testGreeter    `vroomy:",auto"`
+		Named   *greeterPlugin `vroomy:"greeter"`
+	}
+
+	m := makeDependenciesMap(map[string]Plugin{
+		"a":       &A{},
+		"greeter": &greeterPlugin{},
+	})
+
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]int{
+		"Greeter": {1},
+		"Named":   {2},
+	}
+
+	dm := m["a"]
+	if len(dm) != len(want) {
+		t.Fatalf("invalid number of dependencies, expected %d and received %d (%v)", len(want), len(dm), dm)
+	}
+
+	for fieldPath, indices := range want {
+		dep, ok := dm[fieldPath]
+		switch {
+		case !ok:
+			t.Fatalf("invalid dependencies, expected field <%s> and received %v", fieldPath, dm)
+		case dep.key != "greeter":
+			t.Fatalf("invalid key for field <%s>, expected <%s> and received <%s>", fieldPath, "greeter", dep.key)
+		case !intSliceEqual(indices, dep.indices):
+			t.Fatalf("invalid indices for field <%s>, expected %v and received %v", fieldPath, indices, dep.indices)
+		}
+	}
+
+	if want := []string{"greeter"}; !stringSliceEqual(want, dm.pluginKeys()) {
+		t.Fatalf("invalid plugin keys, expected %v and received %v", want, dm.pluginKeys())
+	}
+}
+
 func makeErrorsList(errs ...string) error {
 	var errorlist errors.ErrorList
 	for _, msg := range errs {
@@ -371,7 +581,7 @@ func stringSliceEqual(a, b []string) (equal bool) {
 
 func errorEqual(a, b error) (equal bool) {
 	al, alok := a.(*errors.ErrorList)
-	bl, blok := a.(*errors.ErrorList)
+	bl, blok := b.(*errors.ErrorList)
 	if alok && blok {
 		return errorslistEqual(al, bl)
 	}
@@ -411,13 +621,20 @@ func errorslistEqual(a, b *errors.ErrorList) (equal bool) {
 	return stringSliceEqual(ae, be)
 }
 
-func dependencyMapEqual(a, b dependencyMap) (equal bool) {
+// dependencyMapEqual compares the expected indices by key with the dependencies of a map, which is keyed by field path
+func dependencyMapEqual(a map[string][]int, b dependencyMap) (equal bool) {
 	if len(a) != len(b) {
 		return
 	}
 
+	byKey := make(map[string][]int, len(b))
+	for _, dep := range b {
+		byKey[dep.key] = dep.indices
+	}
+
 	for k, v := range a {
-		if !intSliceEqual(v, b[k]) {
+		indices, ok := byKey[k]
+		if !ok || !intSliceEqual(v, indices) {
 			return
 		}
 	}
@@ -425,7 +642,7 @@ func dependencyMapEqual(a, b dependencyMap) (equal bool) {
 	return true
 }
 
-func dependenciesMapEqual(a, b dependenciesMap) (equal bool) {
+func dependenciesMapEqual(a map[string]map[string][]int, b dependenciesMap) (equal bool) {
 	if len(a) != len(b) {
 		return
 	}
@@ -0,0 +1,219 @@
+package vroomy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// dependencyOptionOptional will leave the field unset when the dependency has not been registered
+	dependencyOptionOptional = "optional"
+	// dependencyOptionLazy will inject a resolver rather than the backend, allowing soft cycles
+	dependencyOptionLazy = "lazy"
+	// dependencyOptionAuto will resolve the dependency by the type of the field rather than by key
+	dependencyOptionAuto = "auto"
+
+	// dependencyKeyCollection will populate a slice or map field with every matching plugin backend
+	dependencyKeyCollection = "*"
+)
+
+var lazyResolverType = reflect.TypeOf((*lazyResolver)(nil)).Elem()
+
+func newDependency(tag string, indices []int, field reflect.StructField) (d dependency) {
+	spl := strings.Split(tag, ",")
+	d.key = strings.TrimSpace(spl[0])
+	d.indices = indices
+	d.field = field.Name
+	d.fieldType = field.Type
+	d.reserved = isReservedKey(d.key)
+	for _, option := range spl[1:] {
+		switch option = strings.TrimSpace(option); option {
+		case dependencyOptionOptional:
+			d.optional = true
+		case dependencyOptionLazy:
+			d.lazy = true
+		case dependencyOptionAuto:
+			d.auto = true
+
+		default:
+			d.invalidOptions = append(d.invalidOptions, option)
+		}
+	}
+
+	return
+}
+
+func newAutoDependency(indices []int, field reflect.StructField, r *typeResolver) (d dependency) {
+	d.indices = indices
+	d.field = field.Name
+	d.fieldType = field.Type
+	d.auto = true
+	return d.resolve(r)
+}
+
+// dependency represents a plugin field which is populated by the backend of another plugin
+type dependency struct {
+	// key is the plugin key (or reserved key) of the dependency, or a placeholder when the key is not a plugin key
+	key       string
+	indices   []int
+	fieldType reflect.Type
+
+	// optional dependencies are left unset when the dependency has not been registered
+	optional bool
+	// lazy dependencies are provided a resolver and are not required to be loaded first
+	lazy bool
+	// auto dependencies are resolved by the type of the field rather than by key
+	auto bool
+	// collection dependencies populate a slice or map field with every matching plugin backend
+	collection bool
+	// typed dependencies were resolved by the type of the field
+	typed bool
+	// members are the plugin keys of a collection dependency
+	members []string
+
+	// placeholder is true when the dependency key is not a plugin key
+	placeholder bool
+	// reserved dependencies are framework services (e.g. @env) rather than plugins
+	reserved bool
+
+	field          string
+	invalidOptions []string
+
+	// Error encountered while resolving the dependency (if any)
+	err error
+}
+
+// resolve will resolve the key of an auto dependency by type
+func (d dependency) resolve(r *typeResolver) (out dependency) {
+	d.typed = true
+	valueType := d.getValueType()
+	keys := r.Matches(valueType)
+	switch {
+	case len(keys) == 1:
+		d.key = keys[0]
+		return d
+	case len(keys) > 1:
+		d.err = fmt.Errorf("error resolving field <%s> by type: ambiguous type %v, matched by plugins %v", d.field, valueType, keys)
+	case !d.optional:
+		d.err = fmt.Errorf("error resolving field <%s> by type: no plugin backend matches type %v", d.field, valueType)
+	}
+
+	// Use a placeholder key which cannot collide with a plugin key
+	d.key = fmt.Sprintf("<auto %s>", d.field)
+	d.placeholder = true
+	return d
+}
+
+// resolveCollection will resolve the members of a collection dependency by type
+func (d dependency) resolveCollection(r *typeResolver) (out dependency) {
+	d.collection = true
+	d.placeholder = true
+	d.typed = true
+	if elemType, ok := getCollectionElem(d.fieldType); ok {
+		d.members = r.Matches(elemType)
+	}
+
+	// Use a placeholder key which cannot collide with a plugin key
+	d.key = fmt.Sprintf("<collection %s>", d.field)
+	return d
+}
+
+// getPluginKeys will return the plugin keys referenced by the dependency
+func (d *dependency) getPluginKeys() (keys []string) {
+	switch {
+	case d.collection:
+		return d.members
+	case d.placeholder, d.reserved:
+		return nil
+
+	default:
+		return []string{d.key}
+	}
+}
+
+// isTyped will return whether or not the dependency was resolved by type and can be set to the provided backend type
+func (d *dependency) isTyped(backendType reflect.Type) bool {
+	if !d.typed {
+		return false
+	}
+
+	if !d.collection {
+		return isSettableType(d.getValueType(), backendType)
+	}
+
+	elemType, ok := getCollectionElem(d.fieldType)
+	return ok && isSettableType(elemType, backendType)
+}
+
+// getValueType will return the type of the value being injected
+// Note: For lazy dependencies, this is the type being lazily resolved
+func (d *dependency) getValueType() reflect.Type {
+	if !d.lazy || !reflect.PointerTo(d.fieldType).Implements(lazyResolverType) {
+		return d.fieldType
+	}
+
+	lr := reflect.New(d.fieldType).Interface().(lazyResolver)
+	return lr.valueType()
+}
+
+func (d *dependency) validate() (err error) {
+	key := d.key
+	if len(d.invalidOptions) > 0 {
+		return fmt.Errorf("invalid vroomy tag options for <%s>: %v", key, d.invalidOptions)
+	}
+
+	if !d.auto && len(key) == 0 {
+		return fmt.Errorf("invalid vroomy tag for field <%s>, key cannot be empty", d.field)
+	}
+
+	if d.reserved {
+		return validateService(key, d)
+	}
+
+	if d.collection {
+		return d.validateCollection()
+	}
+
+	if !d.lazy || d.fieldType == nil {
+		return
+	}
+
+	if !reflect.PointerTo(d.fieldType).Implements(lazyResolverType) {
+		return fmt.Errorf("invalid field type for lazy dependency <%s>, expected vroomy.Lazy and received %v", key, d.fieldType)
+	}
+
+	return
+}
+
+func (d *dependency) validateCollection() (err error) {
+	if d.lazy || d.auto {
+		return fmt.Errorf("invalid vroomy tag for collection field <%s>, cannot be lazy or auto", d.field)
+	}
+
+	if _, ok := getCollectionElem(d.fieldType); !ok {
+		return fmt.Errorf("invalid field type for collection field <%s>, expected slice or map with string keys and received %v", d.field, d.fieldType)
+	}
+
+	return
+}
+
+// isOrdered returns whether or not the dependency must be loaded before the dependent plugin
+func (d *dependency) isOrdered() bool {
+	return !d.lazy && !d.reserved
+}
+
+// getCollectionElem will return the element type of a slice or string-keyed map
+func getCollectionElem(rtype reflect.Type) (elem reflect.Type, ok bool) {
+	switch {
+	case rtype == nil:
+		return
+	case rtype.Kind() == reflect.Slice:
+		return rtype.Elem(), true
+	case rtype.Kind() == reflect.Map && rtype.Key().Kind() == reflect.String:
+		return rtype.Elem(), true
+
+	default:
+		return
+	}
+}
@@ -0,0 +1,237 @@
+package vroomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// GraphFormatDOT is the Graphviz DOT graph format
+	GraphFormatDOT = "dot"
+	// GraphFormatMermaid is the Mermaid graph format
+	GraphFormatMermaid = "mermaid"
+	// GraphFormatJSON is the JSON graph format
+	GraphFormatJSON = "json"
+)
+
+func newGraph(d dependenciesMap) (g *Graph, err error) {
+	var out Graph
+	out.Nodes = d.keys()
+	for _, key := range out.Nodes {
+		dm := d[key]
+		for _, fieldPath := range dm.keys() {
+			dep := dm[fieldPath]
+			for _, pluginKey := range dep.getPluginKeys() {
+				if _, ok := d[pluginKey]; !ok {
+					// Unregistered optional dependency, no edge to add
+					continue
+				}
+
+				// Note: Multiple fields can reference the same plugin in the same manner, which is a single edge
+				if e := newGraphEdge(key, pluginKey, dep); !slices.Contains(out.Edges, e) {
+					out.Edges = append(out.Edges, e)
+				}
+			}
+		}
+	}
+
+	out.LoadOrder, err = d.loadOrder()
+	if cerr, ok := err.(*CycleError); ok {
+		out.Cycle = cerr.Path
+	}
+
+	g = &out
+	return
+}
+
+func newGraphEdge(from, to string, dep dependency) (e GraphEdge) {
+	e.From = from
+	e.To = to
+	e.Optional = dep.optional
+	e.Lazy = dep.lazy
+	e.Auto = dep.auto
+	e.Collection = dep.collection
+	return
+}
+
+// Graph represents the plugin dependency graph
+type Graph struct {
+	// Nodes are the plugin keys, sorted
+	Nodes []string `json:"nodes"`
+	// Edges are the dependencies between plugins
+	Edges []GraphEdge `json:"edges"`
+	// LoadOrder is the order in which plugins are loaded (empty when a cycle exists)
+	LoadOrder []string `json:"loadOrder"`
+	// Cycle is the circular import path (if any)
+	Cycle []string `json:"cycle,omitempty"`
+	// Omitted are the dependency fields resolved by type which are not included (e.g. "users.Greeter"), as backends
+	// cannot be resolved by type before the plugins have been initialized
+	Omitted []string `json:"omitted,omitempty"`
+}
+
+// GraphEdge represents a dependency between two plugins
+type GraphEdge struct {
+	// From is the plugin which has the dependency
+	From string `json:"from"`
+	// To is the plugin being depended on
+	To string `json:"to"`
+
+	// Optional is true when the dependency is tagged as optional
+	Optional bool `json:"optional,omitempty"`
+	// Lazy is true when the dependency is tagged as lazy, lazy edges do not affect load order
+	Lazy bool `json:"lazy,omitempty"`
+	// Auto is true when the dependency was resolved by type
+	Auto bool `json:"auto,omitempty"`
+	// Collection is true when the dependency is a member of a collection field
+	Collection bool `json:"collection,omitempty"`
+}
+
+func (e *GraphEdge) dotAttributes() (attrs []string) {
+	switch {
+	case e.Lazy:
+		attrs = append(attrs, "style=dashed")
+	case e.Optional:
+		attrs = append(attrs, "style=dotted")
+	}
+
+	return
+}
+
+func (e *GraphEdge) mermaidArrow() string {
+	switch {
+	case e.Lazy:
+		return "-. lazy .->"
+	case e.Optional:
+		return "-. optional .->"
+
+	default:
+		return "-->"
+	}
+}
+
+// DOT will return the graph in Graphviz DOT format
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph vroomy {\n")
+	if len(g.Omitted) > 0 {
+		fmt.Fprintf(&sb, "\t// %s\n", g.omittedNote())
+	}
+
+	for _, key := range g.Nodes {
+		fmt.Fprintf(&sb, "\t%q [label=%q];\n", key, g.nodeLabel(key))
+	}
+
+	for _, e := range g.Edges {
+		attrs := e.dotAttributes()
+		if g.isCycleEdge(e) {
+			attrs = append(attrs, "color=red")
+		}
+
+		if len(attrs) == 0 {
+			fmt.Fprintf(&sb, "\t%q -> %q;\n", e.From, e.To)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\t%q -> %q [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid will return the graph in Mermaid flowchart format
+func (g *Graph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("graph TD\n")
+	if len(g.Omitted) > 0 {
+		fmt.Fprintf(&sb, "\t%%%% %s\n", g.omittedNote())
+	}
+
+	for i, key := range g.Nodes {
+		fmt.Fprintf(&sb, "\tn%d[\"%s\"]\n", i, g.nodeLabel(key))
+	}
+
+	ids := g.nodeIDs()
+	for _, e := range g.Edges {
+		arrow := e.mermaidArrow()
+		if g.isCycleEdge(e) {
+			arrow = "-. cycle .->"
+		}
+
+		fmt.Fprintf(&sb, "\tn%d %s n%d\n", ids[e.From], arrow, ids[e.To])
+	}
+
+	return sb.String()
+}
+
+// JSON will return the graph in JSON format
+func (g *Graph) JSON() (bs []byte, err error) {
+	return json.MarshalIndent(g, "", "\t")
+}
+
+// Format will return the graph in the provided format (dot, mermaid or json)
+func (g *Graph) Format(format string) (out string, err error) {
+	switch strings.ToLower(format) {
+	case GraphFormatDOT:
+		return g.DOT(), nil
+	case GraphFormatMermaid:
+		return g.Mermaid(), nil
+	case GraphFormatJSON:
+		var bs []byte
+		if bs, err = g.JSON(); err != nil {
+			return
+		}
+
+		return string(bs), nil
+
+	default:
+		err = fmt.Errorf("invalid graph format, <%s> is not supported", format)
+		return
+	}
+}
+
+func (g *Graph) omittedNote() string {
+	return fmt.Sprintf("Dependencies resolved by type are omitted, plugins have not been initialized: %s", strings.Join(g.Omitted, ", "))
+}
+
+func (g *Graph) nodeLabel(key string) string {
+	for i, loaded := range g.LoadOrder {
+		if loaded == key {
+			return fmt.Sprintf("%s (%d)", key, i+1)
+		}
+	}
+
+	return key
+}
+
+func (g *Graph) nodeIDs() (ids map[string]int) {
+	ids = make(map[string]int, len(g.Nodes))
+	for i, key := range g.Nodes {
+		ids[key] = i
+	}
+
+	return
+}
+
+func (g *Graph) isCycleEdge(e GraphEdge) bool {
+	for i := 0; i < len(g.Cycle)-1; i++ {
+		if g.Cycle[i] == e.From && g.Cycle[i+1] == e.To {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CycleError is returned when plugins import each other in a circular manner
+type CycleError struct {
+	// Path is the circular import path, the first and last entries are the same plugin
+	Path []string
+}
+
+// Error will return the error string value
+func (c *CycleError) Error() string {
+	return fmt.Sprintf("circular import error: %s", strings.Join(c.Path, " -> "))
+}
@@ -0,0 +1,119 @@
+package vroomy
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_newGraph(t *testing.T) {
+	type A struct {
+		BasePlugin
+	}
+
+	type B struct {
+		BasePlugin
+
+		A Plugin `vroomy:"a"`
+	}
+
+	type C struct {
+		BasePlugin
+
+		B Plugin `vroomy:"b"`
+	}
+
+	type D struct {
+		BasePlugin
+
+		E Plugin `vroomy:"e"`
+	}
+
+	type E struct {
+		BasePlugin
+
+		D Plugin `vroomy:"d"`
+	}
+
+	g, err := newGraph(makeDependenciesMap(map[string]Plugin{
+		"a": &A{},
+		"b": &B{},
+		"c": &C{},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a", "b", "c"}; !stringSliceEqual(want, g.LoadOrder) {
+		t.Fatalf("invalid load order, expected %v and received %v", want, g.LoadOrder)
+	}
+
+	dot := g.DOT()
+	for _, want := range []string{`"b" -> "a";`, `"c" -> "b";`, `"c" [label="c (3)"];`} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("invalid DOT output, expected to contain <%s> and received:\n%s", want, dot)
+		}
+	}
+
+	if mermaid := g.Mermaid(); !strings.Contains(mermaid, "n2 --> n1") {
+		t.Fatalf("invalid Mermaid output, expected to contain <%s> and received:\n%s", "n2 --> n1", mermaid)
+	}
+
+	g, err = newGraph(makeDependenciesMap(map[string]Plugin{
+		"a": &A{},
+		"d": &D{},
+		"e": &E{},
+	}))
+
+	want := "circular import error: d -> e -> d"
+	if err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+
+	if want := []string{"d", "e", "d"}; !stringSliceEqual(want, g.Cycle) {
+		t.Fatalf("invalid cycle, expected %v and received %v", want, g.Cycle)
+	}
+
+	if dot := g.DOT(); !strings.Contains(dot, `"d" -> "e" [color=red];`) {
+		t.Fatalf("invalid DOT output, expected cycle edge to be highlighted and received:\n%s", dot)
+	}
+}
+
+func TestRegistry_DependencyGraph(t *testing.T) {
+	type B struct {
+		BasePlugin
+
+		A Plugin `vroomy:"a"`
+	}
+
+	r := NewRegistry()
+	if err := r.Register("a", &unloadedBackendPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("b", &B{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("optional", &optionalGreeterPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backends are not called and dependencies resolved by type are omitted, as plugins have not been initialized
+	g, err := r.DependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"optional.Greeter"}; !stringSliceEqual(want, g.Omitted) {
+		t.Fatalf("invalid omitted dependencies, expected %v and received %v", want, g.Omitted)
+	}
+
+	if want := (GraphEdge{From: "b", To: "a"}); len(g.Edges) != 1 || g.Edges[0] != want {
+		t.Fatalf("invalid edges, expected [%v] and received %v", want, g.Edges)
+	}
+
+	want := "// Dependencies resolved by type are omitted, plugins have not been initialized: optional.Greeter"
+	if dot := g.DOT(); !strings.Contains(dot, want) {
+		t.Fatalf("invalid DOT output, expected to contain <%s> and received:\n%s", want, dot)
+	}
+}
@@ -0,0 +1,217 @@
+package vroomy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdbu/errors"
+)
+
+const (
+	// ErrNoListeners is returned when listeners are handed off before any have been bound
+	ErrNoListeners = errors.Error("cannot upgrade, no listeners have been bound")
+	// ErrUpgradeInProgress is returned when an upgrade is requested while another is in progress
+	ErrUpgradeInProgress = errors.Error("cannot upgrade, an upgrade is already in progress")
+)
+
+const (
+	// envListeners is the environment variable containing the listeners passed to a child process (e.g. "http:3,https:4")
+	envListeners = "VROOMY_LISTENERS"
+	// envReadyFD is the environment variable containing the descriptor a child process writes to once it is ready
+	envReadyFD = "VROOMY_READY_FD"
+)
+
+// upgradeTimeout is the maximum time to wait for a child process to report readiness
+const upgradeTimeout = time.Second * 30
+
+// Upgrade will pass the bound listeners to a new instance of the current executable, started with the same
+// arguments. Once the new process reports it is ready, this service stops listening so it can drain and exit.
+// If the new process fails to start or report readiness, this service continues serving
+// Note: Within ListenUntilSignal, an upgrade can be triggered with SIGUSR2
+func (v *Vroomy) Upgrade() (err error) {
+	if !v.upgrading.Set(true) {
+		return ErrUpgradeInProgress
+	}
+	defer v.upgrading.Set(false)
+
+	var (
+		names []string
+		files []*os.File
+	)
+
+	if names, files, err = v.getListenerFiles(); err != nil {
+		return
+	}
+	defer closeFiles(files)
+
+	var r, w *os.File
+	if r, w, err = os.Pipe(); err != nil {
+		return
+	}
+	defer r.Close()
+
+	var cmd *exec.Cmd
+	cmd, err = newUpgradeCommand(names, files, w)
+	w.Close()
+	if err != nil {
+		return
+	}
+
+	log.Printf("Vroomy: Started process %d, waiting for it to report ready", cmd.Process.Pid)
+	if err = waitForReady(r, upgradeTimeout); err != nil {
+		cmd.Process.Kill()
+		err = fmt.Errorf("error upgrading, process %d did not become ready: %v", cmd.Process.Pid, err)
+		return
+	}
+
+	// Release the child process, it is no longer managed by this process
+	cmd.Process.Release()
+	v.handedOff.Set(true)
+	log.Printf("Vroomy: Process %d is ready, handing off listeners", cmd.Process.Pid)
+
+	v.mu.RLock()
+	stop := v.stop
+	v.mu.RUnlock()
+	if stop != nil {
+		stop()
+	}
+
+	return
+}
+
+func (v *Vroomy) upgrade() {
+	if err := v.Upgrade(); err != nil {
+		log.Printf("Vroomy: Error upgrading: %v", err)
+	}
+}
+
+func isUpgradeSignal(sig os.Signal) bool {
+	for _, upgradeSignal := range upgradeSignals {
+		if sig == upgradeSignal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getListenerFiles will return a duplicated file for each of the bound listeners, sorted by name
+func (v *Vroomy) getListenerFiles() (names []string, files []*os.File, err error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if len(v.listeners) == 0 {
+		err = ErrNoListeners
+		return
+	}
+
+	for name := range v.listeners {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		filer, ok := v.listeners[name].(interface{ File() (*os.File, error) })
+		if !ok {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("cannot upgrade, <%s> listener does not support file descriptors", name)
+		}
+
+		var f *os.File
+		if f, err = filer.File(); err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("error getting file of <%s> listener: %v", name, err)
+		}
+
+		files = append(files, f)
+	}
+
+	return
+}
+
+func newUpgradeCommand(names []string, files []*os.File, ready *os.File) (cmd *exec.Cmd, err error) {
+	var executable string
+	if executable, err = os.Executable(); err != nil {
+		return
+	}
+
+	// Extra files begin at descriptor 3, following stdin, stdout and stderr
+	listeners := make([]string, 0, len(names))
+	for i, name := range names {
+		listeners = append(listeners, formatInheritedListener(name, 3+i))
+	}
+
+	cmd = exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, ready)
+	cmd.Env = append(getUpgradeEnv(),
+		fmt.Sprintf("%s=%s", envListeners, strings.Join(listeners, ",")),
+		fmt.Sprintf("%s=%d", envReadyFD, 3+len(files)),
+	)
+
+	err = cmd.Start()
+	return
+}
+
+// getUpgradeEnv will return the environment of the current process without any inherited handoff variables
+func getUpgradeEnv() (env []string) {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListeners+"=") || strings.HasPrefix(kv, envReadyFD+"=") {
+			continue
+		}
+
+		env = append(env, kv)
+	}
+
+	return
+}
+
+func waitForReady(r *os.File, timeout time.Duration) (err error) {
+	errC := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := r.Read(buf)
+		errC <- err
+	}()
+
+	select {
+	case err = <-errC:
+		return
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// notifyParentReady will notify the parent process which passed its listeners that this process is ready
+func notifyParentReady() {
+	value := os.Getenv(envReadyFD)
+	if len(value) == 0 {
+		return
+	}
+
+	os.Unsetenv(envReadyFD)
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Vroomy: Invalid %s of <%s>: %v", envReadyFD, value, err)
+		return
+	}
+
+	f := os.NewFile(uintptr(fd), "ready")
+	defer f.Close()
+	if _, err = f.Write([]byte{1}); err != nil {
+		log.Printf("Vroomy: Error notifying parent process: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package vroomy
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVroomy_getListenerFiles(t *testing.T) {
+	var v Vroomy
+	if _, _, err := v.getListenerFiles(); err != ErrNoListeners {
+		t.Fatalf("invalid error, expected %v and received %v", ErrNoListeners, err)
+	}
+
+	l, err := v.listen(&Listener{Name: ListenerHTTP}, networkTCP, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	names, files, err := v.getListenerFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles(files)
+
+	if want := []string{ListenerHTTP}; !stringSliceEqual(want, names) {
+		t.Fatalf("invalid names, expected %v and received %v", want, names)
+	}
+
+	// Listener files can be used to create a listener sharing the same socket
+	inherited, err := net.FileListener(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inherited.Close()
+
+	if inherited.Addr().String() != l.Addr().String() {
+		t.Fatalf("invalid address, expected %s and received %s", l.Addr(), inherited.Addr())
+	}
+}
+
+func TestVroomy_getListenerFiles_defaultNames(t *testing.T) {
+	var v Vroomy
+	v.cfg = &Config{Listeners: []*Listener{
+		{Address: "127.0.0.1:0"},
+		{Address: unixPrefix + filepath.Join(t.TempDir(), "app.sock")},
+	}}
+
+	v.cfg.setDefaultListenerNames()
+	for _, l := range v.cfg.Listeners {
+		network, addr, err := parseListenAddress(l.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nl, err := v.listen(l, network, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer nl.Close()
+	}
+
+	names, files, err := v.getListenerFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles(files)
+
+	// Listener entries are parsed by the upgraded process, using unused descriptors
+	entries := make([]string, 0, len(names))
+	for i, name := range names {
+		entries = append(entries, formatInheritedListener(name, 1000+i))
+	}
+
+	inherited, err := parseInheritedListeners(strings.Join(entries, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range v.cfg.Listeners {
+		if _, ok := inherited[l.Name]; !ok {
+			t.Fatalf("invalid inherited listeners, expected <%s> to exist and received %v", l.Name, inherited)
+		}
+	}
+}
+
+func Test_waitForReady(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err = w.Write([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = waitForReady(r, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the write side without notifying (e.g. the process exited) is an error
+	w.Close()
+	if err = waitForReady(r, time.Second); err == nil {
+		t.Fatal("expected error for closed pipe and received nil")
+	}
+}
@@ -0,0 +1,90 @@
+package vroomy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	// nextProtoHTTP2 and nextProtoHTTP1 are the ALPN protocol identifiers of HTTP/2 and HTTP/1.1
+	nextProtoHTTP2 = "h2"
+	nextProtoHTTP1 = "http/1.1"
+
+	// minHTTP2FrameSize and maxHTTP2FrameSize are the bounds of the HTTP/2 max frame size setting (RFC 9113)
+	minHTTP2FrameSize = 1 << 14
+	maxHTTP2FrameSize = 1<<24 - 1
+)
+
+// HTTP2Config is the HTTP/2 configuration of the listeners
+type HTTP2Config struct {
+	// H2C will serve cleartext HTTP/2 (with prior knowledge) on HTTP listeners, in addition to HTTP/1
+	H2C bool `toml:"h2c"`
+	// Disable will disable HTTP/2 on HTTPS listeners, which are served using HTTP/2 by default
+	Disable bool `toml:"disable"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams per connection (defaults to 100)
+	MaxConcurrentStreams int `toml:"maxConcurrentStreams"`
+	// MaxReadFrameSize is the largest frame accepted from clients (e.g. "1MB"), between 16KB and 16MB
+	MaxReadFrameSize ByteSize `toml:"maxReadFrameSize"`
+	// SendPingTimeout is the idle duration after which a ping is sent to check the health of a connection
+	SendPingTimeout time.Duration `toml:"sendPingTimeout"`
+}
+
+func (h *HTTP2Config) validate() (err error) {
+	if h.MaxConcurrentStreams < 0 {
+		return fmt.Errorf("invalid http2 config, max concurrent streams of <%d> cannot be negative", h.MaxConcurrentStreams)
+	}
+
+	if h.MaxReadFrameSize != 0 && (h.MaxReadFrameSize < minHTTP2FrameSize || h.MaxReadFrameSize > maxHTTP2FrameSize) {
+		return fmt.Errorf("invalid http2 config, max read frame size of <%d> must be between %d and %d", h.MaxReadFrameSize, minHTTP2FrameSize, maxHTTP2FrameSize)
+	}
+
+	return
+}
+
+// isEnabled will return whether or not HTTP/2 is served on HTTP or HTTPS listeners
+func (h *HTTP2Config) isEnabled(isTLS bool) bool {
+	if isTLS {
+		return !h.Disable
+	}
+
+	return h.H2C
+}
+
+// setServer will set the protocols and HTTP/2 settings of a server
+func (h *HTTP2Config) setServer(srv *http.Server, isTLS bool) {
+	var p http.Protocols
+	p.SetHTTP1(true)
+	switch {
+	case !h.isEnabled(isTLS):
+	case isTLS:
+		p.SetHTTP2(true)
+	default:
+		p.SetUnencryptedHTTP2(true)
+	}
+
+	srv.Protocols = &p
+	srv.HTTP2 = &http.HTTP2Config{
+		MaxConcurrentStreams: h.MaxConcurrentStreams,
+		MaxReadFrameSize:     int(h.MaxReadFrameSize),
+		SendPingTimeout:      h.SendPingTimeout,
+	}
+}
+
+// setTLSConfig will set the ALPN protocols of a TLS configuration, advertising HTTP/2 unless it has been disabled
+// Note: Protocols which have already been set (e.g. for ACME challenges) are retained
+func (h *HTTP2Config) setTLSConfig(cfg *tls.Config) {
+	protos := slices.DeleteFunc(slices.Clone(cfg.NextProtos), func(proto string) bool {
+		return proto == nextProtoHTTP2 || proto == nextProtoHTTP1
+	})
+
+	if h.Disable {
+		cfg.NextProtos = append([]string{nextProtoHTTP1}, protos...)
+		return
+	}
+
+	cfg.NextProtos = append([]string{nextProtoHTTP2, nextProtoHTTP1}, protos...)
+}
@@ -0,0 +1,195 @@
+package vroomy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHTTP2Config_validate(t *testing.T) {
+	type testcase struct {
+		cfg         HTTP2Config
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{cfg: HTTP2Config{H2C: true, MaxConcurrentStreams: 250, MaxReadFrameSize: 1 << 20}},
+		{
+			cfg:         HTTP2Config{MaxConcurrentStreams: -1},
+			expectedErr: "invalid http2 config, max concurrent streams of <-1> cannot be negative",
+		},
+		{
+			cfg:         HTTP2Config{MaxReadFrameSize: 1024},
+			expectedErr: "invalid http2 config, max read frame size of <1024> must be between 16384 and 16777215",
+		},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(tc.cfg.validate()); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func TestHTTP2Config_setTLSConfig(t *testing.T) {
+	type testcase struct {
+		cfg      HTTP2Config
+		protos   []string
+		expected []string
+	}
+
+	tcs := []testcase{
+		{protos: nil, expected: []string{"h2", "http/1.1"}},
+		{cfg: HTTP2Config{Disable: true}, protos: nil, expected: []string{"http/1.1"}},
+		{cfg: HTTP2Config{Disable: true}, protos: []string{"h2", "http/1.1", "acme-tls/1"}, expected: []string{"http/1.1", "acme-tls/1"}},
+	}
+
+	for _, tc := range tcs {
+		cfg := tls.Config{NextProtos: tc.protos}
+		tc.cfg.setTLSConfig(&cfg)
+		if !slices.Equal(cfg.NextProtos, tc.expected) {
+			t.Fatalf("invalid protocols, expected %v and received %v", tc.expected, cfg.NextProtos)
+		}
+	}
+}
+
+func TestVroomy_Listen_http2(t *testing.T) {
+	type testcase struct {
+		http2            HTTP2Config
+		expectedHTTP     int
+		expectedHTTPS    int
+		expectedH2CError bool
+	}
+
+	tcs := []testcase{
+		{expectedHTTP: 1, expectedHTTPS: 2, expectedH2CError: true},
+		{http2: HTTP2Config{H2C: true}, expectedHTTP: 2, expectedHTTPS: 2},
+		{http2: HTTP2Config{Disable: true}, expectedHTTP: 1, expectedHTTPS: 1, expectedH2CError: true},
+	}
+
+	for _, tc := range tcs {
+		r := NewRegistry()
+		if err := r.Register("a", &handlerPlugin{}); err != nil {
+			t.Fatal(err)
+		}
+
+		tlsDir := t.TempDir()
+		writeTestCertificate(t, tlsDir, "localhost")
+
+		cfg := newTestConfig(t, "a.Hello")
+		cfg.Listen = "127.0.0.1:0"
+		cfg.TLSListen = "127.0.0.1:0"
+		cfg.TLSDir = tlsDir
+		cfg.AllowNonTLS = true
+		cfg.HTTP2 = tc.http2
+
+		v, err := NewWithRegistry(cfg, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go v.Listen(ctx)
+		awaitReady(t, v)
+
+		addrs := v.Addrs()
+		h2c := newH2CClient()
+		if resp, err := h2c.Get("http://" + addrs[ListenerHTTP].String() + "/hello"); err == nil {
+			resp.Body.Close()
+			if tc.expectedH2CError {
+				t.Fatalf("invalid h2c response, expected error and received %s", resp.Proto)
+			}
+		} else if !tc.expectedH2CError {
+			t.Fatal(err)
+		}
+
+		expectProtocol(t, http.DefaultClient, "http://"+addrs[ListenerHTTP].String()+"/hello", 1)
+		if tc.expectedHTTP == 2 {
+			expectProtocol(t, h2c, "http://"+addrs[ListenerHTTP].String()+"/hello", 2)
+		}
+
+		expectProtocol(t, newTestTLSClient(), "https://"+addrs[ListenerHTTPS].String()+"/hello", tc.expectedHTTPS)
+		cancel()
+		v.Close()
+	}
+}
+
+func newH2CClient() *http.Client {
+	var p http.Protocols
+	p.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: &http.Transport{Protocols: &p}}
+}
+
+func newTestTLSClient() *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+func expectProtocol(t *testing.T, client *http.Client, url string, major int) {
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+	if resp.ProtoMajor != major {
+		t.Fatalf("invalid protocol for <%s>, expected HTTP/%d and received %s", url, major, resp.Proto)
+	}
+}
+
+// writeTestCertificate will write a self-signed certificate pair (name.crt and name.key) for localhost to the directory
+func writeTestCertificate(t *testing.T, dir, name string) {
+	writeTestCertificateWithValidity(t, dir, name, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+}
+
+func writeTestCertificateWithValidity(t *testing.T, dir, name string, notBefore, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, filepath.Join(dir, name+".crt"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(dir, name+".key"), "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, loc, blockType string, bs []byte) {
+	if err := os.WriteFile(loc, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bs}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
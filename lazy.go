@@ -0,0 +1,76 @@
+package vroomy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type lazyResolver interface {
+	setResolver(key string, fn func() (interface{}, error))
+	valueType() reflect.Type
+}
+
+var _ lazyResolver = &Lazy[interface{}]{}
+
+// Lazy is a lazily resolved plugin backend, used by fields tagged with the lazy option:
+//
+//	Search vroomy.Lazy[search.Backend] `vroomy:"search,lazy"`
+//
+// Lazy dependencies are not required to be loaded before the dependent plugin, which
+// allows soft cycles between plugins. The backend is resolved on the first call to Get
+// which occurs after the dependency has been loaded.
+type Lazy[T any] struct {
+	mu sync.Mutex
+
+	key     string
+	resolve func() (interface{}, error)
+
+	resolved bool
+	value    T
+}
+
+// Key will return the key of the plugin being lazily resolved
+func (l *Lazy[T]) Key() string {
+	return l.key
+}
+
+// Get will resolve and return the backend
+func (l *Lazy[T]) Get() (value T, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.resolved {
+		return l.value, nil
+	}
+
+	if l.resolve == nil {
+		err = fmt.Errorf("lazy dependency <%s> has not been provided a resolver", l.key)
+		return
+	}
+
+	var reference interface{}
+	if reference, err = l.resolve(); err != nil {
+		return
+	}
+
+	var ok bool
+	if value, ok = reference.(T); !ok {
+		err = fmt.Errorf("invalid type for lazy dependency <%s>, expected %T and received %T", l.key, value, reference)
+		return
+	}
+
+	l.value = value
+	l.resolved = true
+	return
+}
+
+func (l *Lazy[T]) setResolver(key string, fn func() (interface{}, error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.key = key
+	l.resolve = fn
+}
+
+func (l *Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,114 @@
+package vroomy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vroomy/httpserve"
+)
+
+const (
+	// defaultReadTimeout and defaultWriteTimeout mirror the defaults used by httpserve
+	defaultReadTimeout  = 5 * time.Minute
+	defaultWriteTimeout = 5 * time.Minute
+	// defaultMaxHeaderBytes mirrors the default used by httpserve
+	defaultMaxHeaderBytes = 16384
+)
+
+// byteSizeUnits are the supported size units, KB, MB and GB are multiples of 1024
+var byteSizeUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{suffix: "KIB", size: 1 << 10},
+	{suffix: "MIB", size: 1 << 20},
+	{suffix: "GIB", size: 1 << 30},
+	{suffix: "KB", size: 1 << 10},
+	{suffix: "MB", size: 1 << 20},
+	{suffix: "GB", size: 1 << 30},
+	{suffix: "B", size: 1},
+}
+
+// ByteSize is a size in bytes, which is configured as a string with an optional unit (e.g. "512", "64KB" or "10MB")
+type ByteSize int64
+
+// ParseByteSize will parse a size with an optional unit of B, KB, MB or GB (e.g. "10MB")
+func ParseByteSize(value string) (b ByteSize, err error) {
+	str := strings.ToUpper(strings.TrimSpace(value))
+	unit := ByteSize(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+
+	var n int64
+	if n, err = strconv.ParseInt(str, 10, 64); err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size <%s>, expected a positive number with an optional unit (e.g. \"10MB\")", value)
+	}
+
+	return ByteSize(n) * unit, nil
+}
+
+// UnmarshalText will parse a text size value
+func (b *ByteSize) UnmarshalText(text []byte) (err error) {
+	*b, err = ParseByteSize(string(text))
+	return
+}
+
+// limitedBody is a request body limited to a maximum size, which retains the original body so the limit can be overridden
+type limitedBody struct {
+	io.ReadCloser
+
+	body io.ReadCloser
+}
+
+// limitBody will limit the request body to the provided size, replacing any limit which has already been applied
+func limitBody(w http.ResponseWriter, req *http.Request, size ByteSize) {
+	body := req.Body
+	if lb, ok := body.(*limitedBody); ok {
+		body = lb.body
+	}
+
+	req.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, body, int64(size)), body: body}
+}
+
+// newLimitsHandler will return a handler which applies a route or group timeout and maximum body size
+// The timeout overrides the read and write timeouts of the server for matching requests
+func newLimitsHandler(timeout time.Duration, maxBodySize ByteSize) httpserve.Handler {
+	if timeout <= 0 && maxBodySize <= 0 {
+		return nil
+	}
+
+	return func(ctx *httpserve.Context) {
+		w, req := ctx.Writer(), ctx.Request()
+		if timeout > 0 {
+			deadline := time.Now().Add(timeout)
+			rc := http.NewResponseController(w)
+			rc.SetReadDeadline(deadline)
+			rc.SetWriteDeadline(deadline)
+		}
+
+		if maxBodySize > 0 {
+			limitBody(w, req, maxBodySize)
+		}
+	}
+}
+
+// newMaxBodySizeHandler will wrap the provided handler, limiting request bodies to the provided size (when set)
+func newMaxBodySizeHandler(h http.Handler, maxBodySize ByteSize) http.Handler {
+	if maxBodySize <= 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		limitBody(w, req, maxBodySize)
+		h.ServeHTTP(w, req)
+	})
+}
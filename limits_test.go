@@ -0,0 +1,212 @@
+package vroomy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vroomy/httpserve"
+)
+
+type bodyPlugin struct {
+	BasePlugin
+}
+
+func (b *bodyPlugin) Read(ctx *httpserve.Context) {
+	bs, err := io.ReadAll(ctx.Request().Body)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		ctx.WriteString(http.StatusRequestEntityTooLarge, "text/plain", err.Error())
+		return
+	case err != nil:
+		ctx.WriteString(http.StatusBadRequest, "text/plain", err.Error())
+		return
+	}
+
+	ctx.WriteString(http.StatusOK, "text/plain", string(bs))
+}
+
+func TestParseByteSize(t *testing.T) {
+	type testcase struct {
+		value       string
+		expected    ByteSize
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{value: "512", expected: 512},
+		{value: "512B", expected: 512},
+		{value: "64KB", expected: 64 << 10},
+		{value: "10MB", expected: 10 << 20},
+		{value: "1 GiB", expected: 1 << 30},
+		{value: "10mb", expected: 10 << 20},
+		{value: "MB", expectedErr: "invalid size <MB>, expected a positive number with an optional unit (e.g. \"10MB\")"},
+		{value: "-1KB", expectedErr: "invalid size <-1KB>, expected a positive number with an optional unit (e.g. \"10MB\")"},
+	}
+
+	for _, tc := range tcs {
+		size, err := ParseByteSize(tc.value)
+		if errStr := getErrorString(err); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+
+		if size != tc.expected {
+			t.Fatalf("invalid size for <%s>, expected %d and received %d", tc.value, tc.expected, size)
+		}
+	}
+}
+
+func TestConfig_limits(t *testing.T) {
+	var cfg Config
+	if _, err := toml.Decode(`
+readHeaderTimeout = "5s"
+maxBodySize = "1MB"
+
+[[group]]
+name = "exports"
+httpPath = "/exports"
+timeout = "10m"
+
+[[route]]
+httpPath = "/upload"
+maxBodySize = "10MB"
+timeout = "30s"
+`, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ReadHeaderTimeout != time.Second*5 {
+		t.Fatalf("invalid read header timeout, expected %v and received %v", time.Second*5, cfg.ReadHeaderTimeout)
+	}
+
+	if cfg.MaxBodySize != 1<<20 {
+		t.Fatalf("invalid max body size, expected %d and received %d", 1<<20, cfg.MaxBodySize)
+	}
+
+	if timeout := cfg.Groups[0].Timeout; timeout != time.Minute*10 {
+		t.Fatalf("invalid group timeout, expected %v and received %v", time.Minute*10, timeout)
+	}
+
+	if r := cfg.Routes[0]; r.Timeout != time.Second*30 || r.MaxBodySize != 10<<20 {
+		t.Fatalf("invalid route limits, expected 30s and %d and received %v and %d", 10<<20, r.Timeout, r.MaxBodySize)
+	}
+}
+
+func TestVroomy_Listen_maxBodySize(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("b", &bodyPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t)
+	cfg.Listen = "127.0.0.1:0"
+	cfg.MaxBodySize = 16
+	cfg.Groups = []*RouteGroup{{Name: "uploads", HTTPPath: "/uploads", MaxBodySize: 64}}
+	cfg.Routes = []*Route{
+		{Method: "POST", HTTPPath: "/small", Handlers: []string{"b.Read"}},
+		{Method: "POST", HTTPPath: "/large", Handlers: []string{"b.Read"}, MaxBodySize: 1 << 10},
+		{Method: "POST", Group: "uploads", HTTPPath: "/file", Handlers: []string{"b.Read"}},
+	}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+	awaitReady(t, v)
+
+	type testcase struct {
+		path     string
+		size     int
+		expected int
+	}
+
+	tcs := []testcase{
+		{path: "/small", size: 16, expected: http.StatusOK},
+		{path: "/small", size: 17, expected: http.StatusRequestEntityTooLarge},
+		{path: "/large", size: 1 << 10, expected: http.StatusOK},
+		{path: "/large", size: 1<<10 + 1, expected: http.StatusRequestEntityTooLarge},
+		{path: "/uploads/file", size: 64, expected: http.StatusOK},
+		{path: "/uploads/file", size: 65, expected: http.StatusRequestEntityTooLarge},
+	}
+
+	addr := v.describeListener(&Listener{Name: ListenerHTTP})
+	for _, tc := range tcs {
+		resp, err := http.Post("http://"+addr+tc.path, "text/plain", strings.NewReader(strings.Repeat("a", tc.size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+		if resp.StatusCode != tc.expected {
+			t.Fatalf("invalid status code for <%s> with %d bytes, expected %d and received %d", tc.path, tc.size, tc.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestVroomy_Listen_timeout(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("b", &bodyPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t)
+	cfg.Listen = "127.0.0.1:0"
+	cfg.ReadTimeout = time.Millisecond * 100
+	cfg.Routes = []*Route{
+		{Method: "POST", HTTPPath: "/default", Handlers: []string{"b.Read"}},
+		{Method: "POST", HTTPPath: "/slow", Handlers: []string{"b.Read"}, Timeout: time.Second * 5},
+	}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+	awaitReady(t, v)
+
+	type testcase struct {
+		path     string
+		expected int
+	}
+
+	tcs := []testcase{
+		{path: "/default", expected: http.StatusBadRequest},
+		{path: "/slow", expected: http.StatusOK},
+	}
+
+	addr := v.describeListener(&Listener{Name: ListenerHTTP})
+	for _, tc := range tcs {
+		// Body is sent slower than the read timeout of the server
+		pr, pw := io.Pipe()
+		go func() {
+			time.Sleep(time.Millisecond * 300)
+			pw.Write([]byte("hello"))
+			pw.Close()
+		}()
+
+		resp, err := http.Post("http://"+addr+tc.path, "text/plain", pr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+		if resp.StatusCode != tc.expected {
+			t.Fatalf("invalid status code for <%s>, expected %d and received %d", tc.path, tc.expected, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package vroomy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/gdbu/errors"
+)
+
+const (
+	networkTCP  = "tcp"
+	networkUnix = "unix"
+
+	unixPrefix = "unix://"
+	tcpPrefix  = "tcp://"
+)
+
+// parseListenAddress will parse a listen address, either a TCP address ("host:port" or "tcp://host:port")
+// or a Unix domain socket ("unix:///run/app.sock")
+func parseListenAddress(value string) (network, address string, err error) {
+	switch {
+	case strings.HasPrefix(value, unixPrefix):
+		if address = strings.TrimPrefix(value, unixPrefix); len(address) == 0 {
+			return "", "", fmt.Errorf("invalid listen address <%s>, socket path cannot be empty", value)
+		}
+
+		return networkUnix, address, nil
+	default:
+		address = strings.TrimPrefix(value, tcpPrefix)
+		if _, _, err = net.SplitHostPort(address); err != nil {
+			return "", "", fmt.Errorf("invalid listen address <%s>: %v", value, err)
+		}
+
+		return networkTCP, address, nil
+	}
+}
+
+// resolveListenAddress will resolve the socket path of a Unix domain socket address against the configuration directory
+func (c *Config) resolveListenAddress(value string) string {
+	if !strings.HasPrefix(value, unixPrefix) {
+		return value
+	}
+
+	return unixPrefix + c.resolvePath(strings.TrimPrefix(value, unixPrefix))
+}
+
+func (c *Config) validateListeners() (err error) {
+	var errs errors.ErrorList
+	if len(c.Listen) > 0 {
+		_, _, err = parseListenAddress(c.Listen)
+		errs.Push(err)
+	}
+
+	if len(c.TLSListen) > 0 {
+		var network string
+		network, _, err = parseListenAddress(c.TLSListen)
+		errs.Push(err)
+		if network == networkUnix && !c.AllowNonTLS && len(c.getDefaultAddress(c.Listen, c.Port)) > 0 {
+			// HTTP requests are redirected to the port of the HTTPS listener, which a socket does not have
+			errs.Push(fmt.Errorf("invalid tls listen address <%s>, HTTP requests cannot be redirected to a Unix domain socket unless allowNonTLS is set", c.TLSListen))
+		}
+	}
+
+	if len(c.SocketMode) > 0 {
+		_, err = parseSocketMode(c.SocketMode)
+		errs.Push(err)
+	}
+
+	names := make(map[string]struct{}, len(c.Listeners))
+	for _, l := range c.Listeners {
+		name := l.getName()
+		if _, ok := names[name]; ok {
+			errs.Push(fmt.Errorf("invalid listener <%s>, name has already been used", name))
+		}
+
+		names[name] = struct{}{}
+		errs.Push(l.validate(c))
+	}
+
+	return errs.Err()
+}
+
+// setDefaultListenerNames will name each unnamed listener by its address
+func (c *Config) setDefaultListenerNames() {
+	for _, l := range c.Listeners {
+		l.Name = l.getName()
+	}
+}
+
+// listenUnix will bind a Unix domain socket, removing a stale socket file left by a previous process
+func listenUnix(loc, mode, owner string) (l net.Listener, err error) {
+	if err = removeStaleSocket(loc); err != nil {
+		return
+	}
+
+	var ul *net.UnixListener
+	if ul, err = net.ListenUnix(networkUnix, &net.UnixAddr{Name: loc, Net: networkUnix}); err != nil {
+		return
+	}
+
+	// Socket files are removed by vroomy, so they are kept when listeners are handed off to a new process
+	ul.SetUnlinkOnClose(false)
+	if err = setSocketPermissions(loc, mode, owner); err != nil {
+		ul.Close()
+		os.Remove(loc)
+		return
+	}
+
+	return ul, nil
+}
+
+func setSocketPermissions(loc, mode, owner string) (err error) {
+	if len(mode) > 0 {
+		var fm os.FileMode
+		if fm, err = parseSocketMode(mode); err != nil {
+			return
+		}
+
+		if err = os.Chmod(loc, fm); err != nil {
+			return fmt.Errorf("error setting mode of socket <%s>: %v", loc, err)
+		}
+	}
+
+	if len(owner) == 0 {
+		return
+	}
+
+	var uid, gid int
+	if uid, gid, err = parseSocketOwner(owner); err != nil {
+		return
+	}
+
+	if err = os.Chown(loc, uid, gid); err != nil {
+		return fmt.Errorf("error setting owner of socket <%s>: %v", loc, err)
+	}
+
+	return
+}
+
+func parseSocketMode(mode string) (fm os.FileMode, err error) {
+	var m uint64
+	if m, err = strconv.ParseUint(mode, 8, 32); err != nil {
+		return 0, fmt.Errorf("invalid socket mode <%s>, expected octal file mode (e.g. \"0660\")", mode)
+	}
+
+	return os.FileMode(m), nil
+}
+
+// parseSocketOwner will parse an owner of "user", "user:group" or ":group", where user and group are names or ids
+// Unset user or group ids are returned as -1, which leaves them unchanged
+func parseSocketOwner(owner string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+	username, group, _ := strings.Cut(owner, ":")
+	if len(username) > 0 {
+		if uid, err = lookupID(username, lookupUserID); err != nil {
+			return -1, -1, fmt.Errorf("invalid socket owner <%s>: %v", owner, err)
+		}
+	}
+
+	if len(group) > 0 {
+		if gid, err = lookupID(group, lookupGroupID); err != nil {
+			return -1, -1, fmt.Errorf("invalid socket owner <%s>: %v", owner, err)
+		}
+	}
+
+	return
+}
+
+func lookupID(value string, lookup func(string) (string, error)) (id int, err error) {
+	if id, err = strconv.Atoi(value); err == nil {
+		return
+	}
+
+	var idStr string
+	if idStr, err = lookup(value); err != nil {
+		return
+	}
+
+	return strconv.Atoi(idStr)
+}
+
+func lookupUserID(name string) (id string, err error) {
+	var u *user.User
+	if u, err = user.Lookup(name); err != nil {
+		return
+	}
+
+	return u.Uid, nil
+}
+
+func lookupGroupID(name string) (id string, err error) {
+	var g *user.Group
+	if g, err = user.LookupGroup(name); err != nil {
+		return
+	}
+
+	return g.Gid, nil
+}
@@ -0,0 +1,133 @@
+package vroomy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_parseListenAddress(t *testing.T) {
+	type testcase struct {
+		value           string
+		expectedNetwork string
+		expectedAddress string
+		expectedErr     string
+	}
+
+	tcs := []testcase{
+		{value: "127.0.0.1:8080", expectedNetwork: networkTCP, expectedAddress: "127.0.0.1:8080"},
+		{value: "tcp://[::]:443", expectedNetwork: networkTCP, expectedAddress: "[::]:443"},
+		{value: "unix:///run/app.sock", expectedNetwork: networkUnix, expectedAddress: "/run/app.sock"},
+		{value: "unix://", expectedErr: "invalid listen address <unix://>, socket path cannot be empty"},
+		{value: "8080", expectedErr: "invalid listen address <8080>: address 8080: missing port in address"},
+	}
+
+	for _, tc := range tcs {
+		network, address, err := parseListenAddress(tc.value)
+		if errStr := getErrorString(err); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%v>", tc.expectedErr, err)
+		}
+
+		if network != tc.expectedNetwork {
+			t.Fatalf("invalid network, expected <%s> and received <%s>", tc.expectedNetwork, network)
+		}
+
+		if address != tc.expectedAddress {
+			t.Fatalf("invalid address, expected <%s> and received <%s>", tc.expectedAddress, address)
+		}
+	}
+}
+
+func Test_parseSocketOwner(t *testing.T) {
+	type testcase struct {
+		owner       string
+		expectedUID int
+		expectedGID int
+	}
+
+	tcs := []testcase{
+		{owner: "1000", expectedUID: 1000, expectedGID: -1},
+		{owner: "1000:1001", expectedUID: 1000, expectedGID: 1001},
+		{owner: ":1001", expectedUID: -1, expectedGID: 1001},
+	}
+
+	for _, tc := range tcs {
+		uid, gid, err := parseSocketOwner(tc.owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if uid != tc.expectedUID || gid != tc.expectedGID {
+			t.Fatalf("invalid owner, expected %d:%d and received %d:%d", tc.expectedUID, tc.expectedGID, uid, gid)
+		}
+	}
+}
+
+func TestVroomy_Listen_unix(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Dir = dir
+	cfg.Listen = "unix://app.sock"
+	cfg.SocketMode = "0600"
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+
+	loc := filepath.Join(dir, "app.sock")
+	deadline := time.Now().Add(time.Second)
+	for v.describeListener(&Listener{Name: ListenerHTTP}) != unixPrefix+loc {
+		if time.Now().After(deadline) {
+			t.Fatalf("invalid listener, expected socket <%s> to be bound", loc)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	info, err := os.Stat(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Fatalf("invalid socket mode, expected %v and received %v", os.FileMode(0600), mode)
+	}
+
+	client := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial(networkUnix, loc)
+		},
+	}}
+
+	resp, err := client.Get("http://unix/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("invalid status code, expected %d and received %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if err = v.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(loc); !os.IsNotExist(err) {
+		t.Fatalf("invalid socket state, expected socket to be removed and received %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package vroomy
+
+import (
+	"fmt"
+	"net"
+)
+
+// Listener is an address to serve on, in addition to the HTTP and HTTPS listeners
+type Listener struct {
+	// Name of the listener, used in logs and to provide or inherit the listener (defaults to the address)
+	Name string `toml:"name"`
+	// Address to bind, either TCP ("127.0.0.1:8080") or a Unix domain socket ("unix:///run/admin.sock")
+	Address string `toml:"address"`
+
+	// TLS will serve HTTPS using the TLS settings of the service
+	TLS bool `toml:"tls"`
+	// TLSDir will serve HTTPS using the certificates within the directory, rather than the TLS settings of the service
+	TLSDir string `toml:"tlsDir"`
+
+	// SocketMode and SocketOwner of Unix domain sockets, default to the settings of the service
+	SocketMode  string `toml:"socketMode"`
+	SocketOwner string `toml:"socketOwner"`
+}
+
+// IsTLS will return whether or not the listener serves HTTPS
+func (l *Listener) IsTLS() bool {
+	return l.TLS || len(l.TLSDir) > 0
+}
+
+// protocol will return the protocol label of the listener
+func (l *Listener) protocol() string {
+	if l.IsTLS() {
+		return "HTTPS"
+	}
+
+	return "HTTP"
+}
+
+// label will return the label used to describe the listener in logs
+func (l *Listener) label() string {
+	switch l.Name {
+	case ListenerHTTP, ListenerHTTPS:
+		return l.protocol()
+
+	default:
+		return fmt.Sprintf("%s, %s", l.Name, l.protocol())
+	}
+}
+
+func (l *Listener) validate(c *Config) (err error) {
+	name := l.getName()
+	switch name {
+	case ListenerHTTP, ListenerHTTPS, ListenerAdmin:
+		return fmt.Errorf("invalid listener <%s>, name is reserved", name)
+	}
+
+	if len(l.Address) == 0 {
+		return fmt.Errorf("invalid listener <%s>, address cannot be empty", name)
+	}
+
+	if _, _, err = parseListenAddress(l.Address); err != nil {
+		return fmt.Errorf("invalid listener <%s>: %v", name, err)
+	}
+
+	if l.TLS && len(l.TLSDir) == 0 && !c.hasTLSDir() && !c.hasAutoCert() {
+		return fmt.Errorf("invalid listener <%s>: %v", name, ErrInvalidTLSDirectory)
+	}
+
+	if len(l.SocketMode) > 0 {
+		if _, err = parseSocketMode(l.SocketMode); err != nil {
+			return fmt.Errorf("invalid listener <%s>: %v", name, err)
+		}
+	}
+
+	return
+}
+
+// getName will return the name of the listener, listeners are named by their address by default
+func (l *Listener) getName() string {
+	if len(l.Name) == 0 {
+		return l.Address
+	}
+
+	return l.Name
+}
+
+// getListeners will return the listeners of the configuration, beginning with the HTTPS and HTTP listeners (when configured)
+// The admin listener (when configured) is last
+func (c *Config) getListeners() (ls []*Listener) {
+	if address := c.getDefaultAddress(c.TLSListen, c.TLSPort); len(address) > 0 {
+		ls = append(ls, c.newDefaultListener(ListenerHTTPS, address, true))
+	}
+
+	if address := c.getDefaultAddress(c.Listen, c.Port); len(address) > 0 {
+		ls = append(ls, c.newDefaultListener(ListenerHTTP, address, false))
+	}
+
+	ls = append(ls, c.Listeners...)
+	if c.Admin != nil {
+		ls = append(ls, c.Admin.listener())
+	}
+
+	return
+}
+
+// getListener will return the listener with the provided name
+func (c *Config) getListener(name string) (l *Listener, ok bool) {
+	for _, l = range c.getListeners() {
+		if l.Name == name {
+			return l, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c *Config) getDefaultAddress(listen string, port uint16) (address string) {
+	switch {
+	case len(listen) > 0:
+		return listen
+	case port > 0:
+		return fmt.Sprintf(":%d", port)
+
+	default:
+		return ""
+	}
+}
+
+func (c *Config) newDefaultListener(name, address string, isTLS bool) *Listener {
+	return &Listener{
+		Name:        name,
+		Address:     address,
+		TLS:         isTLS,
+		SocketMode:  c.SocketMode,
+		SocketOwner: c.SocketOwner,
+	}
+}
+
+// getListeners will return the listeners to serve, including HTTP and HTTPS listeners which have only been provided
+// The HTTPS listener is first, so it is bound before the HTTP listener redirects requests to it
+func (v *Vroomy) getListeners() (ls []*Listener) {
+	ls = v.cfg.getListeners()
+	if _, ok := v.cfg.getListener(ListenerHTTPS); !ok && v.hasListener(ListenerHTTPS) {
+		ls = append([]*Listener{v.cfg.newDefaultListener(ListenerHTTPS, "", true)}, ls...)
+	}
+
+	if _, ok := v.cfg.getListener(ListenerHTTP); !ok && v.hasListener(ListenerHTTP) {
+		ls = append(ls, v.cfg.newDefaultListener(ListenerHTTP, "", false))
+	}
+
+	return
+}
+
+// describeAddress will return the socket path, port (for all interfaces) or address of a bound listener
+func describeAddress(addr net.Addr) string {
+	switch addr := addr.(type) {
+	case *net.UnixAddr:
+		return unixPrefix + addr.Name
+	case *net.TCPAddr:
+		if addr.IP == nil || addr.IP.IsUnspecified() {
+			return fmt.Sprintf("port %d", addr.Port)
+		}
+
+		return addr.String()
+
+	default:
+		return addr.String()
+	}
+}
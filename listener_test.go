@@ -0,0 +1,127 @@
+package vroomy
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestConfig_validateListeners(t *testing.T) {
+	type testcase struct {
+		listeners   []*Listener
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{
+			listeners: []*Listener{{Address: "127.0.0.1:8080"}, {Name: "metrics", Address: "unix:///run/metrics.sock"}},
+		},
+		{
+			listeners:   []*Listener{{Name: "http", Address: "127.0.0.1:8080"}},
+			expectedErr: "invalid listener <http>, name is reserved",
+		},
+		{
+			listeners:   []*Listener{{Name: "admin", Address: "127.0.0.1:9090"}},
+			expectedErr: "invalid listener <admin>, name is reserved",
+		},
+		{
+			listeners:   []*Listener{{Address: "127.0.0.1:8080"}, {Address: "127.0.0.1:8080"}},
+			expectedErr: "invalid listener <127.0.0.1:8080>, name has already been used",
+		},
+		{
+			listeners:   []*Listener{{Name: "metrics"}},
+			expectedErr: "invalid listener <metrics>, address cannot be empty",
+		},
+		{
+			listeners:   []*Listener{{Name: "public", Address: "[::]:443", TLS: true}},
+			expectedErr: "invalid listener <public>: " + ErrInvalidTLSDirectory.Error(),
+		},
+	}
+
+	for _, tc := range tcs {
+		cfg := Config{Listeners: tc.listeners}
+		if errStr := getErrorString(cfg.validateListeners()); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func TestConfig_validateListeners_tlsListen(t *testing.T) {
+	type testcase struct {
+		cfg         Config
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{cfg: Config{Port: 8080, TLSListen: "127.0.0.1:8443"}},
+		{cfg: Config{TLSListen: "unix:///run/app.sock"}},
+		{cfg: Config{Port: 8080, TLSListen: "unix:///run/app.sock", AllowNonTLS: true}},
+		{
+			cfg:         Config{Listen: "127.0.0.1:8080", TLSListen: "unix:///run/app.sock"},
+			expectedErr: "invalid tls listen address <unix:///run/app.sock>, HTTP requests cannot be redirected to a Unix domain socket unless allowNonTLS is set",
+		},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(tc.cfg.validateListeners()); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func TestConfig_setDefaultListenerNames(t *testing.T) {
+	cfg := Config{Listeners: []*Listener{{Address: "127.0.0.1:8080"}, {Name: "metrics", Address: "127.0.0.1:9090"}}}
+	if err := cfg.validateListeners(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validation does not modify the configuration
+	if name := cfg.Listeners[0].Name; name != "" {
+		t.Fatalf("invalid name after validation, expected <> and received <%s>", name)
+	}
+
+	cfg.setDefaultListenerNames()
+	for i, expected := range []string{"127.0.0.1:8080", "metrics"} {
+		if name := cfg.Listeners[i].Name; name != expected {
+			t.Fatalf("invalid name, expected <%s> and received <%s>", expected, name)
+		}
+	}
+
+	loaded, err := NewConfigFromReader(strings.NewReader("[[listener]]\naddress = \"127.0.0.1:8080\"\n"), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := loaded.Listeners[0].Name; name != "127.0.0.1:8080" {
+		t.Fatalf("invalid name of loaded listener, expected <%s> and received <%s>", "127.0.0.1:8080", name)
+	}
+}
+
+func TestVroomy_Listen_listeners(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Listeners = []*Listener{
+		{Name: "metrics", Address: "127.0.0.1:0"},
+		{Name: "internal", Address: "127.0.0.1:0"},
+	}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+
+	awaitReady(t, v)
+	for _, l := range cfg.Listeners {
+		expectStatus(t, "http://"+v.describeListener(l)+"/hello", http.StatusOK)
+	}
+}
@@ -0,0 +1,305 @@
+package vroomy
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// ListenerHTTP is the name of the HTTP listener
+	ListenerHTTP = "http"
+	// ListenerHTTPS is the name of the HTTPS listener
+	ListenerHTTPS = "https"
+)
+
+const (
+	// envSystemdPID, envSystemdFDs and envSystemdFDNames are set by systemd for socket activated services
+	envSystemdPID     = "LISTEN_PID"
+	envSystemdFDs     = "LISTEN_FDS"
+	envSystemdFDNames = "LISTEN_FDNAMES"
+	// systemdFirstFD is the first file descriptor passed by systemd
+	systemdFirstFD = 3
+	// systemdUnnamed is the name systemd passes for sockets without a FileDescriptorName
+	systemdUnnamed = "unknown"
+)
+
+// SetListener will set a pre-created listener to serve for the provided name (ListenerHTTP, ListenerHTTPS or the
+// name of a configured listener) rather than binding the configured address. The HTTP and HTTPS listeners are served
+// even when the corresponding port is not configured
+// Note: Listeners must be set before calling Listen, HTTPS listeners are wrapped with the configured TLS settings
+func (v *Vroomy) SetListener(name string, l net.Listener) (err error) {
+	switch name {
+	case ListenerHTTP, ListenerHTTPS:
+	default:
+		if _, ok := v.cfg.getListener(name); !ok {
+			return fmt.Errorf("invalid listener name <%s>, expected <%s>, <%s> or a configured listener", name, ListenerHTTP, ListenerHTTPS)
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.provided == nil {
+		v.provided = make(map[string]net.Listener)
+	}
+
+	v.provided[name] = l
+	return
+}
+
+// hasListener will return whether or not a listener has been provided or inherited for the provided name
+func (v *Vroomy) hasListener(name string) bool {
+	v.mu.RLock()
+	_, ok := v.provided[name]
+	v.mu.RUnlock()
+	return ok || hasInheritedListener(name)
+}
+
+// takeListener will return the listener which was provided or inherited for the provided name
+// Listeners are owned when they were inherited from a previous vroomy process, rather than provided by the
+// caller or systemd, in which case their socket files are managed by vroomy
+func (v *Vroomy) takeListener(name string) (l net.Listener, ok, owned bool, err error) {
+	v.mu.Lock()
+	if l, ok = v.provided[name]; ok {
+		delete(v.provided, name)
+	}
+	v.mu.Unlock()
+
+	if ok {
+		return
+	}
+
+	return getInheritedListener(name)
+}
+
+// getPort will return the port of the bound listener for the provided name, or the configured port if not bound
+func (v *Vroomy) getPort(name string, configured uint16) uint16 {
+	v.mu.RLock()
+	l, ok := v.listeners[name]
+	v.mu.RUnlock()
+	if !ok {
+		return configured
+	}
+
+	addr, ok := l.Addr().(*net.TCPAddr)
+	if !ok {
+		return configured
+	}
+
+	return uint16(addr.Port)
+}
+
+var inherited = struct {
+	once  sync.Once
+	mu    sync.Mutex
+	err   error
+	files map[string]*os.File
+	// systemd are the names of the listeners which were passed by systemd
+	systemd map[string]bool
+}{}
+
+func loadInheritedListeners() {
+	inherited.once.Do(func() {
+		if inherited.files, inherited.err = parseInheritedListeners(os.Getenv(envListeners)); inherited.err != nil {
+			return
+		}
+
+		var files map[string]*os.File
+		if files, inherited.err = getSystemdListeners(); inherited.err != nil {
+			return
+		}
+
+		inherited.systemd = make(map[string]bool, len(files))
+		for name, f := range files {
+			if _, ok := inherited.files[name]; ok {
+				continue
+			}
+
+			inherited.files[name] = f
+			inherited.systemd[name] = true
+		}
+	})
+}
+
+func hasInheritedListener(name string) (ok bool) {
+	loadInheritedListeners()
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	_, ok = inherited.files[name]
+	return
+}
+
+// getInheritedListener will return the listener for the provided name which was passed by a parent process
+// or by systemd socket activation. Each inherited listener can only be taken once
+func getInheritedListener(name string) (l net.Listener, ok, owned bool, err error) {
+	loadInheritedListeners()
+	if inherited.err != nil {
+		return nil, false, false, inherited.err
+	}
+
+	inherited.mu.Lock()
+	f, ok := inherited.files[name]
+	owned = !inherited.systemd[name]
+	delete(inherited.files, name)
+	inherited.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	defer f.Close()
+	l, err = net.FileListener(f)
+	return
+}
+
+func parseInheritedListeners(value string) (files map[string]*os.File, err error) {
+	files = make(map[string]*os.File)
+	if len(value) == 0 {
+		return
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		// Names are escaped, the descriptor follows the last colon
+		i := strings.LastIndexByte(entry, ':')
+		if i == -1 {
+			return nil, fmt.Errorf("invalid listener entry <%s>, expected name:fd", entry)
+		}
+
+		var name string
+		if name, err = url.QueryUnescape(entry[:i]); err != nil {
+			return nil, fmt.Errorf("invalid listener entry <%s>: %v", entry, err)
+		}
+
+		var fd int
+		if fd, err = strconv.Atoi(entry[i+1:]); err != nil {
+			return nil, fmt.Errorf("invalid listener entry <%s>: %v", entry, err)
+		}
+
+		files[name] = os.NewFile(uintptr(fd), name)
+	}
+
+	return
+}
+
+// formatInheritedListener will format a listener entry (name:fd) of the handoff environment
+// Note: Names are escaped, as listeners are named by their address by default (e.g. "127.0.0.1:8080")
+func formatInheritedListener(name string, fd int) string {
+	return fmt.Sprintf("%s:%d", url.QueryEscape(name), fd)
+}
+
+// getSystemdListeners will return the sockets passed by systemd socket activation, by listener name
+func getSystemdListeners() (files map[string]*os.File, err error) {
+	pid, fds, names := os.Getenv(envSystemdPID), os.Getenv(envSystemdFDs), os.Getenv(envSystemdFDNames)
+	if len(fds) == 0 || pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+
+	// Environment is unset so the sockets are not inherited by child processes
+	os.Unsetenv(envSystemdPID)
+	os.Unsetenv(envSystemdFDs)
+	os.Unsetenv(envSystemdFDNames)
+
+	var descriptors map[string]int
+	if descriptors, err = parseSystemdListeners(fds, names); err != nil {
+		return
+	}
+
+	files = make(map[string]*os.File, len(descriptors))
+	for name, fd := range descriptors {
+		files[name] = os.NewFile(uintptr(fd), name)
+	}
+
+	return
+}
+
+// parseSystemdListeners will return the file descriptor of each socket passed by systemd, by listener name
+// Sockets are named by their FileDescriptorName, unnamed sockets are assigned to http and then https
+func parseSystemdListeners(fds, names string) (descriptors map[string]int, err error) {
+	var count int
+	if count, err = strconv.Atoi(fds); err != nil {
+		return nil, fmt.Errorf("invalid %s of <%s>: %v", envSystemdFDs, fds, err)
+	}
+
+	var fdNames []string
+	if len(names) > 0 {
+		fdNames = strings.Split(names, ":")
+	}
+
+	descriptors = make(map[string]int, count)
+	var unnamedFDs []int
+	for i := 0; i < count; i++ {
+		var name string
+		if i < len(fdNames) {
+			name = fdNames[i]
+		}
+
+		if name == "" || name == systemdUnnamed {
+			unnamedFDs = append(unnamedFDs, systemdFirstFD+i)
+			continue
+		}
+
+		if _, ok := descriptors[name]; ok {
+			return nil, fmt.Errorf("invalid socket activation, multiple sockets for <%s>", name)
+		}
+
+		descriptors[name] = systemdFirstFD + i
+	}
+
+	unnamed := []string{ListenerHTTP, ListenerHTTPS}
+	for name := range descriptors {
+		unnamed = removeString(unnamed, name)
+	}
+
+	for _, fd := range unnamedFDs {
+		if len(unnamed) == 0 {
+			return nil, fmt.Errorf("invalid socket activation, unexpected unnamed socket (%d)", fd)
+		}
+
+		descriptors[unnamed[0]] = fd
+		unnamed = unnamed[1:]
+	}
+
+	return
+}
+
+// getSystemdListenerNames will return the names of the sockets passed by systemd which have not been taken, sorted by name
+func getSystemdListenerNames() (names []string) {
+	loadInheritedListeners()
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	for name := range inherited.systemd {
+		if _, ok := inherited.files[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return
+}
+
+// validateSystemdListeners will ensure each socket passed by systemd matches one of the listeners
+// Note: Sockets are not reassigned, so a socket named for another purpose (e.g. "internal") is never served publicly
+func validateSystemdListeners(names []string, ls []*Listener) (err error) {
+	for _, name := range names {
+		if !slices.ContainsFunc(ls, func(l *Listener) bool { return l.Name == name }) {
+			return fmt.Errorf("invalid socket activation, socket <%s> does not match a configured listener", name)
+		}
+	}
+
+	return
+}
+
+func removeString(ss []string, s string) (out []string) {
+	for _, str := range ss {
+		if str != s {
+			out = append(out, str)
+		}
+	}
+
+	return
+}
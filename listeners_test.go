@@ -0,0 +1,153 @@
+package vroomy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_parseInheritedListeners(t *testing.T) {
+	type testcase struct {
+		value         string
+		expectedNames []string
+		expectedErr   string
+	}
+
+	tcs := []testcase{
+		{value: ""},
+		{value: "http:1000,https:1001", expectedNames: []string{"http", "https"}},
+		{value: "127.0.0.1%3A8080:1000,unix%3A%2F%2F%2Frun%2Fapp.sock:1001", expectedNames: []string{"127.0.0.1:8080", "unix:///run/app.sock"}},
+		{value: "http", expectedErr: "invalid listener entry <http>, expected name:fd"},
+		{value: "http:foo", expectedErr: "invalid listener entry <http:foo>: strconv.Atoi: parsing \"foo\": invalid syntax"},
+	}
+
+	for _, tc := range tcs {
+		files, err := parseInheritedListeners(tc.value)
+		if errStr := getErrorString(err); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%v>", tc.expectedErr, err)
+		}
+
+		if len(files) != len(tc.expectedNames) {
+			t.Fatalf("invalid number of files, expected %d and received %d", len(tc.expectedNames), len(files))
+		}
+
+		for _, name := range tc.expectedNames {
+			if _, ok := files[name]; !ok {
+				t.Fatalf("invalid files, expected <%s> to exist", name)
+			}
+		}
+	}
+}
+
+func getErrorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+func Test_parseSystemdListeners(t *testing.T) {
+	type testcase struct {
+		fds         string
+		names       string
+		expectedFDs map[string]int
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{fds: "1", expectedFDs: map[string]int{ListenerHTTP: 3}},
+		{fds: "2", names: "unknown:unknown", expectedFDs: map[string]int{ListenerHTTP: 3, ListenerHTTPS: 4}},
+		{fds: "2", names: "https:http", expectedFDs: map[string]int{ListenerHTTPS: 3, ListenerHTTP: 4}},
+		{fds: "2", names: "unknown:http", expectedFDs: map[string]int{ListenerHTTPS: 3, ListenerHTTP: 4}},
+		{fds: "3", names: "admin:internal:unknown", expectedFDs: map[string]int{ListenerAdmin: 3, "internal": 4, ListenerHTTP: 5}},
+		{fds: "2", names: "http:http", expectedErr: "invalid socket activation, multiple sockets for <http>"},
+		{fds: "3", expectedErr: "invalid socket activation, unexpected unnamed socket (5)"},
+		{fds: "foo", expectedErr: "invalid LISTEN_FDS of <foo>: strconv.Atoi: parsing \"foo\": invalid syntax"},
+	}
+
+	for _, tc := range tcs {
+		descriptors, err := parseSystemdListeners(tc.fds, tc.names)
+		if errStr := getErrorString(err); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%v>", tc.expectedErr, err)
+		}
+
+		if len(descriptors) != len(tc.expectedFDs) {
+			t.Fatalf("invalid number of descriptors, expected %d and received %d", len(tc.expectedFDs), len(descriptors))
+		}
+
+		for name, fd := range tc.expectedFDs {
+			if descriptors[name] != fd {
+				t.Fatalf("invalid descriptor for <%s>, expected %d and received %d", name, fd, descriptors[name])
+			}
+		}
+	}
+}
+
+func Test_validateSystemdListeners(t *testing.T) {
+	type testcase struct {
+		names       []string
+		expectedErr string
+	}
+
+	ls := []*Listener{{Name: ListenerHTTP}, {Name: "internal"}, {Name: ListenerAdmin}}
+	tcs := []testcase{
+		{names: nil},
+		{names: []string{ListenerAdmin, ListenerHTTP, "internal"}},
+		{names: []string{ListenerHTTPS}, expectedErr: "invalid socket activation, socket <https> does not match a configured listener"},
+		{names: []string{"metrics"}, expectedErr: "invalid socket activation, socket <metrics> does not match a configured listener"},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(validateSystemdListeners(tc.names, ls)); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func TestVroomy_SetListener(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	if err = v.SetListener("foo", nil); err == nil {
+		t.Fatal("expected error for invalid listener name and received nil")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = v.SetListener(ListenerHTTP, l); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for v.Port() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("invalid port, expected port of provided listener and received 0")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if port := l.Addr().(*net.TCPAddr).Port; int(v.Port()) != port {
+		t.Fatalf("invalid port, expected %d and received %d", port, v.Port())
+	}
+
+	expectStatus(t, "http://"+l.Addr().String()+"/hello", http.StatusOK)
+}
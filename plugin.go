@@ -6,3 +6,18 @@ type Plugin interface {
 	Backend() interface{}
 	Close() error
 }
+
+// Tester is an optional interface plugins can implement to provide self-tests
+// Test is called after every plugin has been initialized and loaded against a throwaway data directory
+type Tester interface {
+	Test() error
+}
+
+// Reloader is an optional interface plugins can implement to apply environment changes without a restart
+// Reload is called in dependency order after a configuration reload, with the previous and new values of
+// the keys which changed (removed keys are absent from new). Returning an error rejects the change, and
+// plugins which have already been reloaded are called again with the arguments swapped to roll back
+// Note: Routes cannot be registered through the router service from within Reload
+type Reloader interface {
+	Reload(old, new Environment) error
+}
@@ -0,0 +1,145 @@
+package vroomy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/vroomy/httpserve"
+)
+
+const (
+	// PluginStateRegistered is the state of a plugin which has been registered but not yet initialized
+	PluginStateRegistered PluginState = iota
+	// PluginStateInitialized is the state of a plugin which has been initialized but not yet loaded
+	PluginStateInitialized
+	// PluginStateLoaded is the state of a plugin which has been loaded
+	PluginStateLoaded
+	// PluginStateFailed is the state of a plugin which failed to initialize or load
+	PluginStateFailed
+	// PluginStateClosed is the state of a plugin which has been closed
+	PluginStateClosed
+)
+
+// Describer is an optional interface plugins can implement to provide metadata about themselves
+type Describer interface {
+	Describe() PluginDescription
+}
+
+// PluginDescription represents the metadata a plugin provides about itself
+type PluginDescription struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+
+	// RequiredEnv are the environment keys the plugin expects to be set
+	// Note: RequiredEnv is informational, it is not enforced when the plugin is initialized
+	RequiredEnv []string `json:"requiredEnv"`
+	// Handlers are the handlers the plugin intends to expose
+	Handlers []string `json:"handlers"`
+}
+
+// PluginState represents the lifecycle state of a plugin
+type PluginState uint8
+
+// String will return the string representation of the plugin state
+func (p PluginState) String() string {
+	switch p {
+	case PluginStateRegistered:
+		return "registered"
+	case PluginStateInitialized:
+		return "initialized"
+	case PluginStateLoaded:
+		return "loaded"
+	case PluginStateFailed:
+		return "failed"
+	case PluginStateClosed:
+		return "closed"
+
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(p))
+	}
+}
+
+// MarshalText will marshal the plugin state as text
+func (p PluginState) MarshalText() (text []byte, err error) {
+	return []byte(p.String()), nil
+}
+
+// PluginInfo represents the introspected information of a plugin
+type PluginInfo struct {
+	Key string `json:"key"`
+
+	// Description is populated when the plugin implements Describer
+	Description *PluginDescription `json:"description,omitempty"`
+
+	// Dependencies are the plugin keys referenced by the vroomy struct tags
+	Dependencies []string `json:"dependencies"`
+	// Handlers are the handler methods available to routes and groups
+	Handlers []string `json:"handlers"`
+
+	State        PluginState   `json:"state"`
+	LoadDuration time.Duration `json:"loadDuration"`
+	// Err is the error which caused the plugin to fail (if any)
+	Err error `json:"-"`
+}
+
+type pluginStatus struct {
+	state        PluginState
+	loadDuration time.Duration
+	err          error
+}
+
+func newPluginInfo(key string, pi Plugin, dm dependencyMap, status pluginStatus) (info PluginInfo) {
+	info.Key = key
+	if d, ok := pi.(Describer); ok {
+		desc := d.Describe()
+		info.Description = &desc
+	}
+
+	info.Dependencies = dm.pluginKeys()
+	info.Handlers = getPluginHandlers(pi)
+	info.State = status.state
+	info.LoadDuration = status.loadDuration
+	info.Err = status.err
+	return
+}
+
+func getPluginHandlers(pi Plugin) (handlers []string) {
+	rval := reflect.ValueOf(pi)
+	rtype := rval.Type()
+	for i := 0; i < rtype.NumMethod(); i++ {
+		switch rval.Method(i).Interface().(type) {
+		case func(*httpserve.Context):
+		case func(args ...string) (httpserve.Handler, error):
+
+		default:
+			continue
+		}
+
+		handlers = append(handlers, rtype.Method(i).Name)
+	}
+
+	return
+}
+
+func getPluginLabel(key string, pi Plugin) (label string) {
+	d, ok := pi.(Describer)
+	if !ok {
+		return key
+	}
+
+	desc := d.Describe()
+	if len(desc.Version) == 0 {
+		return key
+	}
+
+	return fmt.Sprintf("%s@%s", key, desc.Version)
+}
+
+func sortPluginInfos(infos []PluginInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+}
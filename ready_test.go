@@ -0,0 +1,112 @@
+package vroomy
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVroomy_Listen_ready(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	onListen := make(chan map[string]net.Addr, 1)
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Listen = "127.0.0.1:0"
+	cfg.Listeners = []*Listener{{Name: "internal", Address: "127.0.0.1:0"}}
+	cfg.OnListen = func(addrs map[string]net.Addr) {
+		onListen <- addrs
+	}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	if v.IsReady() {
+		t.Fatal("invalid ready state, expected service to not be ready before listening")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Listen(ctx)
+
+	awaitReady(t, v)
+	if !v.IsReady() {
+		t.Fatal("invalid ready state, expected service to be ready once listening")
+	}
+
+	addrs := <-onListen
+	for _, name := range []string{ListenerHTTP, "internal"} {
+		addr, ok := addrs[name]
+		if !ok {
+			t.Fatalf("invalid addresses, expected <%s> to be included", name)
+		}
+
+		if port := addr.(*net.TCPAddr).Port; port == 0 {
+			t.Fatalf("invalid address of <%s>, expected bound port and received %d", name, port)
+		}
+
+		expectStatus(t, "http://"+addr.String()+"/hello", 200)
+	}
+}
+
+func TestVroomy_Listen_conflict(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	taken, err := net.Listen(networkTCP, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Listen = "127.0.0.1:0"
+	cfg.Listeners = []*Listener{{Name: "internal", Address: taken.Addr().String()}}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- v.Listen(context.Background())
+	}()
+
+	select {
+	case err = <-errC:
+	case <-time.After(time.Second):
+		t.Fatal("invalid listen result, expected bind error to be returned immediately")
+	}
+
+	expected := "error binding <internal> listener"
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("invalid error, expected <%s> prefix and received <%v>", expected, err)
+	}
+
+	if v.IsReady() {
+		t.Fatal("invalid ready state, expected service to not be ready after a bind error")
+	}
+
+	if addrs := v.Addrs(); len(addrs) != 0 {
+		t.Fatalf("invalid addresses, expected bound listeners to be closed and received %v", addrs)
+	}
+}
+
+func awaitReady(t *testing.T, v *Vroomy) {
+	select {
+	case <-v.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("invalid ready state, expected service to be ready")
+	}
+}
@@ -0,0 +1,213 @@
+package vroomy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/gdbu/queue"
+
+	"github.com/gdbu/errors"
+)
+
+var defaultRegistry = NewRegistry()
+
+// ErrNilQueue is returned when plugins are tested asynchronously without a queue
+const ErrNilQueue = errors.Error("cannot test plugins asynchronously, queue cannot be nil")
+
+// NewRegistry will return a new plugin registry
+func NewRegistry() *Registry {
+	var r Registry
+	r.pm = make(map[string]Plugin)
+	return &r
+}
+
+// DefaultRegistry will return the package-level registry used by Register
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Plugins is kept as an alias of Registry for backwards compatibility
+type Plugins = Registry
+
+// Registry manages registered plugins
+type Registry struct {
+	mu sync.RWMutex
+
+	pm map[string]Plugin
+
+	closed bool
+}
+
+// Register will register a new plugin by key
+func (r *Registry) Register(key string, pi Plugin) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		err = errors.ErrIsClosed
+		return
+	}
+
+	if _, ok := r.pm[key]; ok {
+		return fmt.Errorf("plugin with the key of <%s> has already been loaded", key)
+	}
+
+	r.pm[key] = pi
+	return
+}
+
+// Get will get a plugin by it's key
+func (r *Registry) Get(key string) (pi Plugin, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		err = errors.ErrIsClosed
+		return
+	}
+
+	var ok bool
+	if pi, ok = r.pm[key]; !ok {
+		err = fmt.Errorf("plugin with key of <%s> has not been registered", key)
+		return
+	}
+
+	return
+}
+
+// Loaded will return a copy of the registered plugins
+func (r *Registry) Loaded() (pm map[string]Plugin) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pm = make(map[string]Plugin, len(r.pm))
+	for key, val := range r.pm {
+		pm[key] = val
+	}
+
+	return
+}
+
+// DependencyGraph will return the dependency graph of the registered plugins
+// Note: Plugins have not been initialized, so dependencies resolved by type are omitted from the graph (see Graph.Omitted)
+// When a circular import exists, the graph is returned along with a *CycleError
+func (r *Registry) DependencyGraph() (g *Graph, err error) {
+	// Backends are not called, as they may not be set until the plugins have been initialized
+	dms, omitted := makeDependenciesMapWithBackends(r.Loaded(), nil).withoutTyped()
+	g, err = newGraph(dms)
+	g.Omitted = omitted
+	return
+}
+
+// Test will initialize and load all of the plugins against a throwaway data directory, and
+// then run the self-tests for each plugin which implements Tester (sequentially)
+// Note: Plugins are closed once testing has completed, the registry is closed and cannot be used afterwards
+func (r *Registry) Test(env Environment) (results TestResults, err error) {
+	return r.test(env, func(pm map[string]Plugin) (results TestResults) {
+		results = make(TestResults, 0, len(pm))
+		for key, pi := range pm {
+			results = append(results, runTest(key, pi))
+		}
+
+		return
+	})
+}
+
+// TestAsync will initialize and load all of the plugins against a throwaway data directory, and
+// then run the self-tests for each plugin which implements Tester (asynchronously using the provided queue)
+// Note: Plugins are closed once testing has completed, the registry is closed and cannot be used afterwards
+func (r *Registry) TestAsync(q *queue.Queue, env Environment) (results TestResults, err error) {
+	if q == nil {
+		err = ErrNilQueue
+		return
+	}
+
+	return r.test(env, func(pm map[string]Plugin) (results TestResults) {
+		var wg sync.WaitGroup
+		wg.Add(len(pm))
+
+		results = make(TestResults, len(pm))
+		var i int
+		for key, pi := range pm {
+			q.New(func(i int, key string, pi Plugin) func() {
+				return func() {
+					defer wg.Done()
+					results[i] = runTest(key, pi)
+				}
+			}(i, key, pi))
+
+			i++
+		}
+
+		wg.Wait()
+		return
+	})
+}
+
+func (r *Registry) test(env Environment, fn func(pm map[string]Plugin) TestResults) (results TestResults, err error) {
+	// The plugins are closed by testing, so the registry is closed to prevent them from being used or closed again
+	if err = r.setClosed(); err != nil {
+		return
+	}
+
+	var dir string
+	if dir, err = makeTestDir(); err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var v Vroomy
+	v.cfg = &Config{}
+	v.cfg.Environment = newTestEnvironment(env, dir)
+	v.srv = newRouter()
+	v.pm = r.Loaded()
+
+	if err = v.initPlugins(); err != nil {
+		return
+	}
+	defer v.closePlugins()
+
+	if err = v.loadPlugins(); err != nil {
+		return
+	}
+
+	results = fn(v.pm)
+	results.sort()
+	return
+}
+
+// setClosed will mark the registry as closed, without closing the plugins
+func (r *Registry) setClosed() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errors.ErrIsClosed
+	}
+
+	r.closed = true
+	return
+}
+
+// Close will close plugins
+func (r *Registry) Close() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errors.ErrIsClosed
+	}
+
+	var errs errors.ErrorList
+	log.Println("Vroomy.Plugins: Closing plugins")
+	for key, pi := range r.pm {
+		if err = pi.Close(); err != nil {
+			errs.Push(fmt.Errorf("error closing %s: %v", key, err))
+			continue
+		}
+
+		log.Printf("Vroomy.Plugins: Closed %s\n", key)
+	}
+
+	r.closed = true
+	return errs.Err()
+}
@@ -0,0 +1,113 @@
+package vroomy
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gdbu/errors"
+	"github.com/gdbu/queue"
+)
+
+type testerPlugin struct {
+	BasePlugin
+
+	dataDir string
+	err     error
+}
+
+func (t *testerPlugin) Init(env Environment) error {
+	t.dataDir = env["dataDir"]
+	return nil
+}
+
+func (t *testerPlugin) Test() error {
+	if _, err := os.Stat(t.dataDir); err != nil {
+		return err
+	}
+
+	return t.err
+}
+
+func TestRegistry_Test(t *testing.T) {
+	ps := newTestRegistry(t)
+	results, err := ps.Test(Environment{"dataDir": "data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testResults(t, results)
+
+	// Plugins have been closed by testing, so the registry cannot be used or closed again
+	if _, err = ps.Test(nil); err != errors.ErrIsClosed {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", errors.ErrIsClosed, err)
+	}
+
+	if err = ps.Close(); err != errors.ErrIsClosed {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", errors.ErrIsClosed, err)
+	}
+}
+
+func TestRegistry_TestAsync(t *testing.T) {
+	q := queue.New(2, 4)
+	defer q.Close()
+
+	ps := newTestRegistry(t)
+	results, err := ps.TestAsync(q, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testResults(t, results)
+
+	if _, err = newTestRegistry(t).TestAsync(nil, nil); err != ErrNilQueue {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrNilQueue, err)
+	}
+}
+
+func newTestRegistry(t *testing.T) (ps *Registry) {
+	ps = NewRegistry()
+	if err := ps.Register("a", &testerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ps.Register("b", &testerPlugin{err: errors.Error("foo")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ps.Register("c", &BasePlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func testResults(t *testing.T, results TestResults) {
+	if len(results) != 3 {
+		t.Fatalf("invalid number of results, expected %d and received %d", 3, len(results))
+	}
+
+	if results.Failed() != 1 {
+		t.Fatalf("invalid number of failures, expected %d and received %d", 1, results.Failed())
+	}
+
+	for _, r := range results {
+		switch r.Key {
+		case "a":
+			if r.Err != nil || r.Skipped {
+				t.Fatalf("invalid result for <a>: %s", r.String())
+			}
+		case "b":
+			if r.Err == nil || r.Err.Error() != "foo" {
+				t.Fatalf("invalid result for <b>: %s", r.String())
+			}
+		case "c":
+			if !r.Skipped {
+				t.Fatalf("invalid result for <c>: %s", r.String())
+			}
+		}
+	}
+
+	if err := results.Err(); err == nil {
+		t.Fatal("expected aggregated error and received nil")
+	}
+}
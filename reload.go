@@ -0,0 +1,351 @@
+package vroomy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdbu/errors"
+)
+
+const (
+	// ErrReloadUnsupported is returned when a configuration has no source to be reloaded from
+	ErrReloadUnsupported = errors.Error("cannot reload configuration, configuration was not read from a file or file system")
+)
+
+// configWatchInterval is the interval at which configuration sources are checked for modifications
+const configWatchInterval = time.Second * 2
+
+// ReloadResult is the result of a configuration reload
+type ReloadResult struct {
+	// Number of groups and routes being served after the reload
+	Groups int
+	Routes int
+
+	// Environment keys which were added, changed or removed
+	Environment []string
+	// Plugins which were reloaded with the environment changes, in dependency order
+	Reloaded []string
+
+	// RestartRequired lists the configuration settings which changed and will not be applied until restart
+	RestartRequired []string
+}
+
+// Reload will re-read the configuration from its source and apply route and group changes without a restart
+func (v *Vroomy) Reload() (result *ReloadResult, err error) {
+	if v.cfg.load == nil {
+		err = ErrReloadUnsupported
+		return
+	}
+
+	var cfg *Config
+	if cfg, err = v.cfg.load(); err != nil {
+		err = fmt.Errorf("error reading config: %v", err)
+		return
+	}
+
+	// Programmatic overrides are not part of the configuration source
+	cfg.applyEnvOverrides(v.cfg.overrides)
+	cfg.setDefaultDataDir()
+	return v.ReloadWithConfig(cfg)
+}
+
+// ReloadWithConfig will apply the route, group and environment changes of the provided configuration without a restart
+// The router is rebuilt and swapped in atomically, requests which are in-flight are completed by the previous router
+// Environment changes are provided to plugins which implement Reloader, if any plugin rejects the change the reload fails
+// Note: Changes to other settings (such as plugins or ports) require a restart
+func (v *Vroomy) ReloadWithConfig(cfg *Config) (result *ReloadResult, err error) {
+	v.reloadMu.Lock()
+	defer v.reloadMu.Unlock()
+
+	if v.closed.Get() {
+		err = errors.ErrIsClosed
+		return
+	}
+
+	cfg.setDefaultListenerNames()
+	if err = cfg.validateSettings(); err != nil {
+		err = fmt.Errorf("error validating config: %v", err)
+		return
+	}
+
+	if !v.cfg.ChangeDir {
+		if err = cfg.resolvePaths(); err != nil {
+			err = fmt.Errorf("error resolving paths: %v", err)
+			return
+		}
+	}
+
+	var srv, adminSrv *router
+	if srv, err = v.newServe(cfg); err != nil {
+		return
+	}
+
+	if adminSrv, err = v.initAdmin(cfg); err != nil {
+		return
+	}
+
+	result = newReloadResult(v.cfg, cfg)
+	if result.Reloaded, err = v.reloadEnvironment(v.cfg.Environment, cfg.Environment); err != nil {
+		result = nil
+		return
+	}
+
+	if len(result.Environment) > 0 && v.hasNonReloaders() {
+		// Plugins which do not implement Reloader will not receive the changes until restart
+		result.RestartRequired = append(result.RestartRequired, "env")
+	}
+
+	v.mu.Lock()
+	// Environment is replaced rather than modified, as plugins may retain and read the previous environment
+	v.cfg.Environment = cfg.Environment
+	v.cfg.Groups = cfg.Groups
+	v.cfg.Routes = cfg.Routes
+	v.cfg.Include = cfg.Include
+	v.cfg.sources = cfg.sources
+	if v.cfg.Admin != nil && cfg.Admin != nil {
+		// The admin listener is bound at start, so only its routes, groups and token are reloaded
+		admin := *v.cfg.Admin
+		admin.Token = cfg.Admin.Token
+		admin.Groups = cfg.Admin.Groups
+		admin.Routes = cfg.Admin.Routes
+		v.cfg.Admin = &admin
+	}
+
+	v.srv = srv
+	v.mu.Unlock()
+
+	v.handler.set(srv)
+	if adminSrv != nil && v.cfg.Admin != nil {
+		v.admin.set(adminSrv)
+	}
+
+	log.Printf("Vroomy: Reloaded %d groups and %d routes", result.Groups, result.Routes)
+	if len(result.Reloaded) > 0 {
+		log.Printf("Vroomy: Reloaded environment of %s", strings.Join(result.Reloaded, ", "))
+	}
+
+	if len(result.RestartRequired) > 0 {
+		log.Printf("Vroomy: Changes to %s require a restart", strings.Join(result.RestartRequired, ", "))
+	}
+
+	return
+}
+
+// newServe will build a new router from the routes registered by plugins and the routes and groups of the configuration
+func (v *Vroomy) newServe(cfg *Config) (srv *router, err error) {
+	srv = newRouter()
+	srv.SetOnError(v.cfg.ErrorLogger)
+	for _, r := range v.routes {
+		if err = r.register(srv); err != nil {
+			err = fmt.Errorf("error initializing plugin route <%s %s>: %v", r.method, r.route, err)
+			return
+		}
+	}
+
+	if err = v.initGroups(cfg, srv); err != nil {
+		err = fmt.Errorf("error initializing groups: %v", err)
+		return
+	}
+
+	if err = v.initRoutes(cfg, srv); err != nil {
+		err = fmt.Errorf("error initializing routes: %v", err)
+		return
+	}
+
+	return
+}
+
+// reloadEnvironment will provide the environment changes to each Reloader in dependency order
+// When a plugin rejects the changes, the plugins which were already reloaded are rolled back in reverse order
+func (v *Vroomy) reloadEnvironment(current, next Environment) (reloaded []string, err error) {
+	old, new := diffEnvironment(current, next)
+	if len(old) == 0 && len(new) == 0 {
+		return
+	}
+
+	var order []string
+	if order, err = v.getDependencies().loadOrder(); err != nil {
+		return
+	}
+
+	for _, key := range order {
+		r, ok := v.pm[key].(Reloader)
+		if !ok {
+			continue
+		}
+
+		if err = r.Reload(old, new); err != nil {
+			err = fmt.Errorf("plugin <%s> rejected environment change: %v", getPluginLabel(key, v.pm[key]), err)
+			v.rollbackEnvironment(reloaded, old, new)
+			reloaded = nil
+			return
+		}
+
+		reloaded = append(reloaded, key)
+	}
+
+	return
+}
+
+func (v *Vroomy) rollbackEnvironment(reloaded []string, old, new Environment) {
+	for i := len(reloaded) - 1; i >= 0; i-- {
+		key := reloaded[i]
+		if err := v.pm[key].(Reloader).Reload(new, old); err != nil {
+			log.Printf("Vroomy: Error rolling back environment of %s: %v", getPluginLabel(key, v.pm[key]), err)
+		}
+	}
+}
+
+func (v *Vroomy) hasNonReloaders() bool {
+	for _, p := range v.pm {
+		if _, ok := p.(Reloader); !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *Vroomy) reload() {
+	if _, err := v.Reload(); err != nil {
+		log.Printf("Vroomy: Error reloading config: %v", err)
+	}
+}
+
+func (v *Vroomy) getConfigSources() (sources []string) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.cfg.sources
+}
+
+// watchConfig will reload the configuration when any of its sources are modified
+func (v *Vroomy) watchConfig(ctx context.Context) {
+	if !v.cfg.WatchConfig || v.cfg.load == nil {
+		return
+	}
+
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	modified := getModTimes(v.getConfigSources())
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		current := getModTimes(v.getConfigSources())
+		if maps.Equal(modified, current) {
+			continue
+		}
+
+		v.reload()
+		// Sources may have changed during the reload (e.g. an include was added)
+		modified = getModTimes(v.getConfigSources())
+	}
+}
+
+func newReloadResult(old, new *Config) (r *ReloadResult) {
+	r = &ReloadResult{
+		Groups:          len(new.Groups),
+		Routes:          len(new.Routes),
+		Environment:     getChangedKeys(old.Environment, new.Environment),
+		RestartRequired: getRestartRequired(old, new),
+	}
+
+	return
+}
+
+// diffEnvironment will return the previous and new values of the keys which differ between two environments
+func diffEnvironment(current, next Environment) (old, new Environment) {
+	old = make(Environment)
+	new = make(Environment)
+	for _, key := range getChangedKeys(current, next) {
+		if val, ok := current[key]; ok {
+			old[key] = val
+		}
+
+		if val, ok := next[key]; ok {
+			new[key] = val
+		}
+	}
+
+	return
+}
+
+// getChangedKeys will return the sorted keys which were added, changed or removed between two environments
+func getChangedKeys(current, next map[string]string) (keys []string) {
+	for key, val := range current {
+		if nextVal, ok := next[key]; !ok || nextVal != val {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range next {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+// getRestartRequired will return the settings which differ between two configurations and cannot be reloaded
+func getRestartRequired(old, new *Config) (settings []string) {
+	appendIf := func(changed bool, setting string) {
+		if changed {
+			settings = append(settings, setting)
+		}
+	}
+
+	appendIf(old.Name != new.Name, "name")
+	appendIf(old.Dir != new.Dir, "dir")
+	appendIf(old.ChangeDir != new.ChangeDir, "changeDir")
+	appendIf(old.Port != new.Port, "port")
+	appendIf(old.TLSPort != new.TLSPort, "tlsPort")
+	appendIf(old.TLSDir != new.TLSDir, "tlsDir")
+	appendIf(old.AllowNonTLS != new.AllowNonTLS, "allowNonTLS")
+	appendIf(old.Listen != new.Listen, "listen")
+	appendIf(old.TLSListen != new.TLSListen, "tlsListen")
+	appendIf(old.SocketMode != new.SocketMode || old.SocketOwner != new.SocketOwner, "socket")
+	appendIf(!reflect.DeepEqual(old.Listeners, new.Listeners), "listener")
+	appendIf(!reflect.DeepEqual(getAdminListener(old), getAdminListener(new)), "admin")
+	appendIf(old.ReadTimeout != new.ReadTimeout, "readTimeout")
+	appendIf(old.ReadHeaderTimeout != new.ReadHeaderTimeout, "readHeaderTimeout")
+	appendIf(old.WriteTimeout != new.WriteTimeout, "writeTimeout")
+	appendIf(old.IdleTimeout != new.IdleTimeout, "idleTimeout")
+	appendIf(old.MaxHeaderBytes != new.MaxHeaderBytes, "maxHeaderBytes")
+	appendIf(old.MaxBodySize != new.MaxBodySize, "maxBodySize")
+	appendIf(!reflect.DeepEqual(old.TLS, new.TLS), "tls")
+	appendIf(old.HTTP2 != new.HTTP2, "http2")
+	appendIf(old.AutoCertDir != new.AutoCertDir, "autoCertDir")
+	appendIf(!slices.Equal(old.AutoCertHosts, new.AutoCertHosts), "autoCertHosts")
+	appendIf(!slices.Equal(old.Plugins, new.Plugins) || !slices.Equal(old.IncludeConfig.Plugins, new.IncludeConfig.Plugins), "plugins")
+	return
+}
+
+func getModTimes(locs []string) (modTimes map[string]time.Time) {
+	modTimes = make(map[string]time.Time, len(locs))
+	for _, loc := range locs {
+		info, err := os.Stat(loc)
+		if err != nil {
+			// Missing sources are tracked with a zero time, so their return is noticed
+			modTimes[loc] = time.Time{}
+			continue
+		}
+
+		modTimes[loc] = info.ModTime()
+	}
+
+	return
+}
@@ -0,0 +1,309 @@
+package vroomy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testReloadConfig = `
+dir = %q
+%s
+
+[env]
+dataDir = "data"
+
+[[route]]
+httpPath = "/hello"
+handlers = ["a.Hello"]
+%s
+`
+
+func TestVroomy_Reload(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("services", &servicesPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	loc := filepath.Join(dir, "config.toml")
+	writeTestFile(t, loc, fmt.Sprintf(testReloadConfig, dir, "", ""))
+
+	cfg, err := NewConfig(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.setDefaultDataDir()
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(&v.handler)
+	defer s.Close()
+
+	expectStatus(t, s.URL+"/hello", http.StatusOK)
+	expectStatus(t, s.URL+"/goodbye", http.StatusNotFound)
+
+	writeTestFile(t, loc, fmt.Sprintf(testReloadConfig, dir, "port = 8080", `
+[[route]]
+httpPath = "/goodbye"
+handlers = ["a.Hello"]
+`))
+
+	var result *ReloadResult
+	if result, err = v.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Routes != 2 {
+		t.Fatalf("invalid number of routes, expected %d and received %d", 2, result.Routes)
+	}
+
+	if want := []string{"port"}; !stringSliceEqual(want, result.RestartRequired) {
+		t.Fatalf("invalid restart required, expected %v and received %v", want, result.RestartRequired)
+	}
+
+	expectStatus(t, s.URL+"/hello", http.StatusOK)
+	expectStatus(t, s.URL+"/goodbye", http.StatusOK)
+	// Routes registered by plugins are retained
+	expectStatus(t, s.URL+"/services", http.StatusNoContent)
+
+	writeTestFile(t, loc, fmt.Sprintf(testReloadConfig, dir, "", `
+[[route]]
+httpPath = "/goodbye"
+handlers = ["unknown.Hello"]
+`))
+
+	if _, err = v.Reload(); err == nil {
+		t.Fatal("expected error for handler of unregistered plugin and received nil")
+	}
+
+	// Previous router remains in place when a reload fails
+	expectStatus(t, s.URL+"/goodbye", http.StatusOK)
+}
+
+func TestVroomy_Reload_unsupported(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = v.Reload(); err != ErrReloadUnsupported {
+		t.Fatalf("invalid error, expected %v and received %v", ErrReloadUnsupported, err)
+	}
+}
+
+func writeTestFile(t *testing.T, loc, contents string) {
+	if err := os.WriteFile(loc, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectStatus(t *testing.T, url string, status int) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+	if resp.StatusCode != status {
+		t.Fatalf("invalid status code for <%s>, expected %d and received %d", url, status, resp.StatusCode)
+	}
+}
+
+type reloaderPlugin struct {
+	BasePlugin
+
+	calls  *[]string
+	level  string
+	reject string
+}
+
+func (r *reloaderPlugin) Reload(old, new Environment) error {
+	*r.calls = append(*r.calls, fmt.Sprintf("%s->%s", old["level"], new["level"]))
+	if new["level"] == r.reject {
+		return fmt.Errorf("invalid level <%s>", r.reject)
+	}
+
+	r.level = new["level"]
+	return nil
+}
+
+func (r *reloaderPlugin) Backend() interface{} {
+	return r
+}
+
+type dependentReloaderPlugin struct {
+	reloaderPlugin
+
+	Base *reloaderPlugin `vroomy:"base"`
+}
+
+func TestVroomy_ReloadWithConfig_environment(t *testing.T) {
+	var calls []string
+	base := &reloaderPlugin{calls: &calls, level: "info"}
+	dependent := &dependentReloaderPlugin{reloaderPlugin: reloaderPlugin{calls: &calls, level: "info", reject: "invalid"}}
+
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("dependent", dependent); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("base", base); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t, "a.Hello")
+	cfg.Environment["level"] = "info"
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := newTestConfig(t, "a.Hello")
+	next.Environment["dataDir"] = cfg.Environment["dataDir"]
+	next.Environment["level"] = "debug"
+
+	var result *ReloadResult
+	if result, err = v.ReloadWithConfig(next); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"base", "dependent"}; !stringSliceEqual(want, result.Reloaded) {
+		t.Fatalf("invalid reloaded plugins, expected %v and received %v", want, result.Reloaded)
+	}
+
+	if want := []string{"level"}; !stringSliceEqual(want, result.Environment) {
+		t.Fatalf("invalid environment keys, expected %v and received %v", want, result.Environment)
+	}
+
+	if base.level != "debug" || dependent.level != "debug" {
+		t.Fatalf("invalid levels, expected debug and received %s and %s", base.level, dependent.level)
+	}
+
+	calls = calls[:0]
+	invalid := newTestConfig(t, "a.Hello")
+	invalid.Environment["dataDir"] = cfg.Environment["dataDir"]
+	invalid.Environment["level"] = "invalid"
+
+	want := "plugin <dependent> rejected environment change: invalid level <invalid>"
+	if _, err = v.ReloadWithConfig(invalid); err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+
+	// Base is rolled back after dependent rejects the change
+	if wantCalls := []string{"debug->invalid", "debug->invalid", "invalid->debug"}; !stringSliceEqual(wantCalls, calls) {
+		t.Fatalf("invalid calls, expected %v and received %v", wantCalls, calls)
+	}
+
+	if base.level != "debug" {
+		t.Fatalf("invalid base level, expected debug and received %s", base.level)
+	}
+
+	if level := v.cfg.Environment["level"]; level != "debug" {
+		t.Fatalf("invalid environment level, expected debug and received %s", level)
+	}
+}
+
+func TestVroomy_Reload_admin(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	loc := filepath.Join(dir, "config.toml")
+	writeTestFile(t, loc, fmt.Sprintf(testReloadConfig, dir, "", `
+[admin]
+address = "unix://admin.sock"
+socketMode = "0600"
+socketOwner = ":1000"
+token = "secret"
+`))
+
+	cfg, err := NewConfig(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := *getAdminListener(v.cfg)
+	// Reloading more than once must not report unchanged admin settings as requiring a restart
+	for i := 0; i < 2; i++ {
+		var result *ReloadResult
+		if result, err = v.Reload(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.RestartRequired) > 0 {
+			t.Fatalf("invalid restart required, expected none and received %v", result.RestartRequired)
+		}
+
+		if l := getAdminListener(v.cfg); *l != expected {
+			t.Fatalf("invalid admin listener, expected %+v and received %+v", expected, *l)
+		}
+	}
+}
+
+func TestVroomy_Reload_envOverrides(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	loc := filepath.Join(dir, "config.toml")
+	writeTestFile(t, loc, fmt.Sprintf(testReloadConfig, dir, "", ""))
+
+	cfg, err := NewConfig(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Overrides the configured data directory, as the -dataDir flag does
+	dataDir := filepath.Join(dir, "override")
+	cfg.setEnvOverride("dataDir", dataDir)
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result *ReloadResult
+	if result, err = v.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Environment) > 0 {
+		t.Fatalf("invalid environment changes, expected none and received %v", result.Environment)
+	}
+
+	if len(result.RestartRequired) > 0 {
+		t.Fatalf("invalid restart required, expected none and received %v", result.RestartRequired)
+	}
+
+	if value := v.cfg.Environment["dataDir"]; value != dataDir {
+		t.Fatalf("invalid data directory, expected <%s> and received <%s>", dataDir, value)
+	}
+}
@@ -2,6 +2,7 @@ package vroomy
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/vroomy/httpserve"
 )
@@ -23,6 +24,11 @@ type Route struct {
 	Target string `toml:"target"`
 	// Plugin handlers
 	Handlers []string `toml:"handlers"`
+
+	// Timeout overrides the read and write timeouts of the server for the route (e.g. "30s")
+	Timeout time.Duration `toml:"timeout"`
+	// MaxBodySize overrides the maximum request body size of the server for the route (e.g. "10MB")
+	MaxBodySize ByteSize `toml:"maxBodySize"`
 }
 
 // String will return a formatted version of the route
@@ -1,6 +1,8 @@
 package vroomy
 
 import (
+	"time"
+
 	"github.com/gdbu/errors"
 	"github.com/vroomy/httpserve"
 )
@@ -22,6 +24,11 @@ type RouteGroup struct {
 	// Plugin handlers
 	Handlers []string `toml:"handlers"`
 
+	// Timeout overrides the read and write timeouts of the server for routes within the group (e.g. "30s")
+	Timeout time.Duration `toml:"timeout"`
+	// MaxBodySize overrides the maximum request body size of the server for routes within the group (e.g. "10MB")
+	MaxBodySize ByteSize `toml:"maxBodySize"`
+
 	HTTPHandlers []httpserve.Handler `toml:"-"`
 
 	G httpserve.Group `toml:"-"`
@@ -0,0 +1,308 @@
+package vroomy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/vroomy/httpserve"
+)
+
+var _ httpserve.Group = &router{}
+
+// newRouter will return a new router
+func newRouter() *router {
+	var r router
+	r.routes = make(map[string][]*routerRoute)
+	r.panic = onRouterPanic
+	r.notFound = r.newHandler([]httpserve.Handler{notFoundHandler})
+	return &r
+}
+
+// router routes requests to the handlers of their matching route
+// Note: httpserve does not expose the router of a Serve, which vroomy needs to serve requests from its own
+// listeners and to swap routers when the configuration is reloaded. Routes are matched by the router, and the
+// handlers of each route are served by an httpserve.Router without routes, whose not found handlers are the
+// handlers of the route
+type router struct {
+	routes   map[string][]*routerRoute
+	notFound *httpserve.Router
+
+	panic   httpserve.PanicHandler
+	onError func(error)
+}
+
+// GET will set a GET endpoint
+func (r *router) GET(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodGet, route, hs...)
+}
+
+// PUT will set a PUT endpoint
+func (r *router) PUT(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodPut, route, hs...)
+}
+
+// POST will set a POST endpoint
+func (r *router) POST(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodPost, route, hs...)
+}
+
+// DELETE will set a DELETE endpoint
+func (r *router) DELETE(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodDelete, route, hs...)
+}
+
+// OPTIONS will set a OPTIONS endpoint
+func (r *router) OPTIONS(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodOptions, route, hs...)
+}
+
+// Handle will create a route for any method
+func (r *router) Handle(method, route string, hs ...httpserve.Handler) (err error) {
+	var rr routerRoute
+	if rr.parts, err = getRouteParts(route); err != nil {
+		return fmt.Errorf("error creating route for [%s] \"%s\": %v", method, route, err)
+	}
+
+	if rr.hasParams() {
+		// Parameters are matched by the router, and set before the handlers of the route
+		hs = append([]httpserve.Handler{setRouteParams}, hs...)
+	}
+
+	rr.h = r.newHandler(hs)
+	r.routes[method] = append(r.routes[method], &rr)
+	return
+}
+
+// Group will return a new group for a given route and handlers
+func (r *router) Group(route string, hs ...httpserve.Handler) httpserve.Group {
+	return &routerPrefix{r: r, route: route, hs: hs}
+}
+
+// SetPanic will set the panic handler
+func (r *router) SetPanic(h httpserve.PanicHandler) {
+	r.panic = h
+	r.forEach(func(h *httpserve.Router) {
+		h.SetPanic(r.panic)
+	})
+}
+
+// SetOnError will set the error handler
+func (r *router) SetOnError(fn func(error)) {
+	r.onError = fn
+	r.forEach(func(h *httpserve.Router) {
+		h.SetOnError(r.onError)
+	})
+}
+
+func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, params := r.match(req.Method, req.URL.Path)
+	if len(params) > 0 {
+		req = req.WithContext(context.WithValue(req.Context(), routeParamsKey{}, params))
+	}
+
+	h.ServeHTTP(w, req)
+}
+
+// match will return the handler of the first route which matches the url, and its parameters
+func (r *router) match(method, url string) (h *httpserve.Router, params httpserve.Params) {
+	for _, rr := range r.routes[method] {
+		var ok bool
+		if params, ok = rr.check(params[:0], url); ok {
+			return rr.h, params
+		}
+	}
+
+	return r.notFound, nil
+}
+
+// newHandler will return an httpserve.Router without routes, which serves every request using the provided handlers
+func (r *router) newHandler(hs []httpserve.Handler) (h *httpserve.Router) {
+	h = &httpserve.Router{}
+	h.SetNotFound(hs...)
+	h.SetPanic(r.panic)
+	if r.onError != nil {
+		h.SetOnError(r.onError)
+	}
+
+	return
+}
+
+func (r *router) forEach(fn func(*httpserve.Router)) {
+	fn(r.notFound)
+	for _, routes := range r.routes {
+		for _, rr := range routes {
+			fn(rr.h)
+		}
+	}
+}
+
+var _ httpserve.Group = &routerPrefix{}
+
+// routerPrefix is a group of routes which share a route prefix and handlers
+type routerPrefix struct {
+	r     *router
+	route string
+	hs    []httpserve.Handler
+}
+
+// GET will set a GET endpoint
+func (p *routerPrefix) GET(route string, hs ...httpserve.Handler) error {
+	return p.Handle(http.MethodGet, route, hs...)
+}
+
+// PUT will set a PUT endpoint
+func (p *routerPrefix) PUT(route string, hs ...httpserve.Handler) error {
+	return p.Handle(http.MethodPut, route, hs...)
+}
+
+// POST will set a POST endpoint
+func (p *routerPrefix) POST(route string, hs ...httpserve.Handler) error {
+	return p.Handle(http.MethodPost, route, hs...)
+}
+
+// DELETE will set a DELETE endpoint
+func (p *routerPrefix) DELETE(route string, hs ...httpserve.Handler) error {
+	return p.Handle(http.MethodDelete, route, hs...)
+}
+
+// OPTIONS will set a OPTIONS endpoint
+func (p *routerPrefix) OPTIONS(route string, hs ...httpserve.Handler) error {
+	return p.Handle(http.MethodOptions, route, hs...)
+}
+
+// Handle will create a route for any method
+func (p *routerPrefix) Handle(method, route string, hs ...httpserve.Handler) error {
+	route, hs = p.join(route, hs)
+	return p.r.Handle(method, route, hs...)
+}
+
+// Group will return a new group for a given route and handlers
+func (p *routerPrefix) Group(route string, hs ...httpserve.Handler) httpserve.Group {
+	route, hs = p.join(route, hs)
+	return &routerPrefix{r: p.r, route: route, hs: hs}
+}
+
+// join will return the route and handlers following the route prefix and handlers of the group
+func (p *routerPrefix) join(route string, hs []httpserve.Handler) (string, []httpserve.Handler) {
+	if len(p.route) > 0 {
+		route = path.Join(p.route, route)
+	}
+
+	if len(p.hs) > 0 {
+		hs = append(append([]httpserve.Handler{}, p.hs...), hs...)
+	}
+
+	return route, hs
+}
+
+// routerRoute is a route which has been split into parts, parameters (":name") and wildcards ("*") are separate parts
+type routerRoute struct {
+	parts []string
+	h     *httpserve.Router
+}
+
+func (rr *routerRoute) hasParams() bool {
+	for _, part := range rr.parts {
+		if part[0] == ':' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check will check a url for a match, it will also return any associated parameters
+func (rr *routerRoute) check(p httpserve.Params, url string) (out httpserve.Params, ok bool) {
+	out = p
+	for _, part := range rr.parts {
+		switch {
+		case len(url) == 0:
+			return
+		case part[0] == ':':
+			// Skip the leading slash, a parameter ends at the next slash
+			n := strings.IndexByte(url[1:], '/')
+			if n == -1 {
+				n = len(url) - 1
+			}
+
+			out = append(out, httpserve.Param{Key: part[1:], Value: url[1 : n+1]})
+			url = url[n+1:]
+		case part[0] == '*':
+			return out, true
+		case isRoutePartMatch(url, part):
+			url = url[len(part):]
+
+		default:
+			return
+		}
+	}
+
+	return out, len(url) == 0
+}
+
+// getRouteParts will split a route into parts, static segments are joined into a single part
+func getRouteParts(route string) (parts []string, err error) {
+	if len(route) == 0 || route[0] != '/' {
+		return nil, httpserve.ErrMissingLeadSlash
+	}
+
+	if route == "/" {
+		return []string{"/"}, nil
+	}
+
+	var buf []byte
+	for _, part := range strings.Split(route, "/") {
+		if len(part) == 0 {
+			continue
+		}
+
+		if part[0] != ':' && part[0] != '*' {
+			buf = append(buf, '/')
+			buf = append(buf, part...)
+			continue
+		}
+
+		if len(buf) > 0 {
+			parts = append(parts, string(buf))
+			buf = buf[:0]
+		}
+
+		parts = append(parts, part)
+	}
+
+	if len(buf) > 0 {
+		parts = append(parts, string(buf))
+	}
+
+	return
+}
+
+// isRoutePartMatch will return whether or not the url begins with the static part, followed by a slash or its end
+func isRoutePartMatch(url, part string) bool {
+	if !strings.HasPrefix(url, part) {
+		return false
+	}
+
+	remaining := url[len(part):]
+	return len(remaining) == 0 || remaining[0] == '/'
+}
+
+// routeParamsKey is the request context key of the parameters matched by the router
+type routeParamsKey struct{}
+
+// setRouteParams will set the parameters matched by the router as the parameters of the context
+func setRouteParams(ctx *httpserve.Context) {
+	ctx.Params, _ = ctx.Request().Context().Value(routeParamsKey{}).(httpserve.Params)
+}
+
+func notFoundHandler(ctx *httpserve.Context) {
+	ctx.WriteString(http.StatusNotFound, "text/plain", "404, not found")
+}
+
+func onRouterPanic(v interface{}) {
+	log.Println("Panic encountered:", v)
+}
@@ -0,0 +1,125 @@
+package vroomy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vroomy/httpserve"
+)
+
+func TestRouter(t *testing.T) {
+	type testcase struct {
+		method       string
+		url          string
+		expectedCode int
+		expectedBody string
+	}
+
+	write := func(body string) httpserve.Handler {
+		return func(ctx *httpserve.Context) {
+			ctx.WriteString(http.StatusOK, "text/plain", body+ctx.Param("id")+ctx.Param("name"))
+		}
+	}
+
+	r := newRouter()
+	if err := r.GET("/", write("index")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.GET("/users/:id", write("user ")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.GET("/users/:id/files/*", write("files ")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.POST("/users", write("create")); err != nil {
+		t.Fatal(err)
+	}
+
+	grp := r.Group("/api", func(ctx *httpserve.Context) {
+		ctx.Put("group", "api")
+	})
+
+	if err := grp.Group("/v1").GET("/hello/:name", func(ctx *httpserve.Context) {
+		ctx.WriteString(http.StatusOK, "text/plain", ctx.Get("group")+" "+ctx.Param("name"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []testcase{
+		{method: "GET", url: "/", expectedCode: http.StatusOK, expectedBody: "index"},
+		{method: "GET", url: "/users/42", expectedCode: http.StatusOK, expectedBody: "user 42"},
+		{method: "GET", url: "/users/42/files/a/b", expectedCode: http.StatusOK, expectedBody: "files 42"},
+		{method: "POST", url: "/users", expectedCode: http.StatusOK, expectedBody: "create"},
+		{method: "GET", url: "/api/v1/hello/world", expectedCode: http.StatusOK, expectedBody: "api world"},
+		{method: "GET", url: "/users", expectedCode: http.StatusNotFound, expectedBody: "404, not found"},
+		{method: "GET", url: "/usersx/42", expectedCode: http.StatusNotFound, expectedBody: "404, not found"},
+		{method: "DELETE", url: "/users/42", expectedCode: http.StatusNotFound, expectedBody: "404, not found"},
+	}
+
+	for _, tc := range tcs {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.url, nil))
+		if rec.Code != tc.expectedCode {
+			t.Fatalf("invalid status code for <%s %s>, expected %d and received %d", tc.method, tc.url, tc.expectedCode, rec.Code)
+		}
+
+		if body := rec.Body.String(); body != tc.expectedBody {
+			t.Fatalf("invalid body for <%s %s>, expected <%s> and received <%s>", tc.method, tc.url, tc.expectedBody, body)
+		}
+	}
+}
+
+func TestRouter_handlers(t *testing.T) {
+	var (
+		calls     []string
+		recovered interface{}
+	)
+
+	r := newRouter()
+	r.SetPanic(func(v interface{}) {
+		recovered = v
+	})
+
+	if err := r.GET("/complete", func(ctx *httpserve.Context) {
+		calls = append(calls, "first")
+		ctx.WriteNoContent()
+	}, func(ctx *httpserve.Context) {
+		calls = append(calls, "second")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.GET("/panic", func(ctx *httpserve.Context) {
+		panic("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/complete", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("invalid status code, expected %d and received %d", http.StatusNoContent, rec.Code)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("invalid calls, expected handlers to stop once the response was completed and received %v", calls)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("invalid status code, expected %d and received %d", http.StatusInternalServerError, rec.Code)
+	}
+
+	if recovered != "boom" {
+		t.Fatalf("invalid panic value, expected <%s> and received <%v>", "boom", recovered)
+	}
+
+	if err := r.GET("users", func(ctx *httpserve.Context) {}); err == nil {
+		t.Fatal("invalid error, expected an error for a route without a leading slash and received nil")
+	}
+}
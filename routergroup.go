@@ -0,0 +1,82 @@
+package vroomy
+
+import (
+	"net/http"
+
+	"github.com/vroomy/httpserve"
+)
+
+var _ httpserve.Group = &routerGroup{}
+
+// routerGroup is the httpserve.Group provided to plugins as the router service
+// Routes are registered to the current router and recorded, so they can be
+// registered again when the router is rebuilt during a reload
+type routerGroup struct {
+	v      *Vroomy
+	groups []pluginGroup
+}
+
+// GET will set a GET endpoint
+func (r *routerGroup) GET(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodGet, route, hs...)
+}
+
+// PUT will set a PUT endpoint
+func (r *routerGroup) PUT(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodPut, route, hs...)
+}
+
+// POST will set a POST endpoint
+func (r *routerGroup) POST(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodPost, route, hs...)
+}
+
+// DELETE will set a DELETE endpoint
+func (r *routerGroup) DELETE(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodDelete, route, hs...)
+}
+
+// OPTIONS will set a OPTIONS endpoint
+func (r *routerGroup) OPTIONS(route string, hs ...httpserve.Handler) error {
+	return r.Handle(http.MethodOptions, route, hs...)
+}
+
+// Handle will create a route for any method
+func (r *routerGroup) Handle(method, route string, hs ...httpserve.Handler) error {
+	return r.v.addPluginRoute(pluginRoute{
+		groups:   r.groups,
+		method:   method,
+		route:    route,
+		handlers: hs,
+	})
+}
+
+// Group will return a new group for a given route and handlers
+func (r *routerGroup) Group(route string, hs ...httpserve.Handler) httpserve.Group {
+	groups := make([]pluginGroup, 0, len(r.groups)+1)
+	groups = append(groups, r.groups...)
+	groups = append(groups, pluginGroup{route: route, handlers: hs})
+	return &routerGroup{v: r.v, groups: groups}
+}
+
+type pluginGroup struct {
+	route    string
+	handlers []httpserve.Handler
+}
+
+// pluginRoute is a route which was registered by a plugin through the router service
+type pluginRoute struct {
+	groups   []pluginGroup
+	method   string
+	route    string
+	handlers []httpserve.Handler
+}
+
+func (p *pluginRoute) register(srv *router) (err error) {
+	var grp httpserve.Group = srv
+	for _, g := range p.groups {
+		grp = grp.Group(g.route, g.handlers...)
+	}
+
+	return grp.Handle(p.method, p.route, p.handlers...)
+}
@@ -0,0 +1,152 @@
+package vroomy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vroomy/httpserve"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// routerHandler serves requests using the current router, which can be swapped atomically
+// Requests which are in-flight during a swap are completed by the router which accepted them
+type routerHandler struct {
+	r atomic.Pointer[router]
+}
+
+func (h *routerHandler) set(r *router) {
+	h.r.Store(r)
+}
+
+func (h *routerHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.r.Load().ServeHTTP(w, req)
+}
+
+// newUpgradeHandler will return a handler which redirects all requests to HTTPS on the provided port
+func newUpgradeHandler(tlsPort uint16) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u := *req.URL
+		u.Scheme = "https"
+		u.Host = fmt.Sprintf("%s:%d", strings.Split(req.Host, ":")[0], tlsPort)
+		http.Redirect(w, req, u.String(), http.StatusMovedPermanently)
+	})
+}
+
+// newHTTPServer will return a server using the timeouts and limits of the configuration
+func newHTTPServer(h http.Handler, addr string, c *Config) *http.Server {
+	var srv http.Server
+	srv.Handler = newMaxBodySizeHandler(h, c.MaxBodySize)
+	srv.Addr = addr
+	srv.ReadTimeout = getDuration(c.ReadTimeout, defaultReadTimeout)
+	srv.ReadHeaderTimeout = c.ReadHeaderTimeout
+	srv.WriteTimeout = getDuration(c.WriteTimeout, defaultWriteTimeout)
+	srv.IdleTimeout = c.IdleTimeout
+	if srv.MaxHeaderBytes = c.MaxHeaderBytes; srv.MaxHeaderBytes <= 0 {
+		srv.MaxHeaderBytes = defaultMaxHeaderBytes
+	}
+
+	return &srv
+}
+
+func getDuration(value, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+// newTLSConfig will return a TLS configuration which serves the current certificates of the provided store
+func newTLSConfig(cs *certificateStore) (cfg *tls.Config) {
+	cfg = &tls.Config{}
+	cfg.GetCertificate = cs.GetCertificate
+	cfg.MinVersion = tls.VersionTLS12
+	cfg.RootCAs = x509.NewCertPool()
+	return
+}
+
+func newAutoCertTLSConfig(ac httpserve.AutoCertConfig) (cfg *tls.Config) {
+	m := &autocert.Manager{
+		Cache:      autocert.DirCache(ac.DirCache),
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: getAutoCertHostPolicy(ac),
+	}
+
+	cfg = m.TLSConfig()
+	cfg.MinVersion = tls.VersionTLS12
+	return
+}
+
+func getAutoCertHostPolicy(ac httpserve.AutoCertConfig) autocert.HostPolicy {
+	if ac.HostPolicy != nil {
+		return ac.HostPolicy
+	}
+
+	return autocert.HostWhitelist(ac.Hosts...)
+}
+
+// loadTLSCertificates will load the certificate pairs (name.crt and name.key) within a directory, in order of name
+func loadTLSCertificates(dir string) (certs []tls.Certificate, err error) {
+	var pairs map[string]*[2]string
+	if pairs, err = getTLSCertificatePairs(dir); err != nil {
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(pairs)) {
+		pair := pairs[name]
+		if len(pair[0]) == 0 || len(pair[1]) == 0 {
+			err = fmt.Errorf("invalid tls certification pair <%s>, neither key nor cert can be empty", name)
+			return
+		}
+
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(pair[0], pair[1]); err != nil {
+			err = fmt.Errorf("invalid tls certification pair <%s>: %v", name, err)
+			return
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return
+}
+
+// getTLSCertificatePairs will return the certificate and key files within a directory, by pair name
+func getTLSCertificatePairs(dir string) (pairs map[string]*[2]string, err error) {
+	pairs = make(map[string]*[2]string)
+	err = filepath.Walk(dir, func(loc string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		ext := filepath.Ext(loc)
+		name := strings.TrimSuffix(loc, ext)
+		pair, ok := pairs[name]
+		if !ok {
+			pair = &[2]string{}
+		}
+
+		switch ext {
+		case ".crt":
+			pair[0] = loc
+		case ".key":
+			pair[1] = loc
+		default:
+			return nil
+		}
+
+		pairs[name] = pair
+		return nil
+	})
+
+	return
+}
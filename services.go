@@ -0,0 +1,55 @@
+package vroomy
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/vroomy/httpserve"
+)
+
+const (
+	// ServiceEnv is the reserved dependency key for the service Environment
+	ServiceEnv = "@env"
+	// ServiceLogger is the reserved dependency key for a *log.Logger prefixed with the plugin key
+	ServiceLogger = "@logger"
+	// ServiceConfig is the reserved dependency key for the service *Config
+	ServiceConfig = "@config"
+	// ServiceDataDir is the reserved dependency key for the data directory (string)
+	ServiceDataDir = "@dataDir"
+	// ServiceRouter is the reserved dependency key for the service router (httpserve.Group)
+	ServiceRouter = "@router"
+)
+
+// reservedPrefix is the prefix used by reserved dependency keys
+const reservedPrefix = "@"
+
+var serviceTypes = map[string]reflect.Type{
+	ServiceEnv:     reflect.TypeOf(Environment{}),
+	ServiceLogger:  reflect.TypeOf(&log.Logger{}),
+	ServiceConfig:  reflect.TypeOf(&Config{}),
+	ServiceDataDir: reflect.TypeOf(""),
+	ServiceRouter:  reflect.TypeOf((*httpserve.Group)(nil)).Elem(),
+}
+
+func isReservedKey(key string) bool {
+	return strings.HasPrefix(key, reservedPrefix)
+}
+
+func validateService(key string, d *dependency) (err error) {
+	serviceType, ok := serviceTypes[key]
+	if !ok {
+		return fmt.Errorf("invalid reserved dependency <%s> for field <%s>", key, d.field)
+	}
+
+	if d.optional || d.lazy || d.auto || d.collection {
+		return fmt.Errorf("invalid vroomy tag for reserved dependency <%s>, options are not supported", key)
+	}
+
+	if d.fieldType != nil && !isSettableType(d.fieldType, serviceType) {
+		return fmt.Errorf("invalid field type for reserved dependency <%s>, expected %v and received %v", key, serviceType, d.fieldType)
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package vroomy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gdbu/errors"
+)
+
+// defaultShutdownTimeout is used when a shutdown timeout has not been configured
+const defaultShutdownTimeout = time.Second * 30
+
+// Shutdown will gracefully shutdown the service
+// Listeners stop accepting, the service is flagged as not ready and in-flight requests are given until the
+// configured shutdown timeout (or the context deadline) to complete. Plugins are then closed in reverse dependency order
+func (v *Vroomy) Shutdown(ctx context.Context) (err error) {
+	if !v.closed.Set(true) {
+		return errors.ErrIsClosed
+	}
+
+	start := time.Now()
+	v.ready.Set(false)
+
+	timeout := v.cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	log.Printf("Vroomy: Shutting down, waiting up to %v for in-flight requests", timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var errs errors.ErrorList
+	if err = v.shutdownServers(ctx); err != nil {
+		log.Printf("Vroomy: Requests did not complete before shutdown deadline (%v), closing remaining connections", err)
+		errs.Push(v.closeServers())
+	} else {
+		log.Printf("Vroomy: Drained connections in %v", time.Since(start))
+	}
+
+	errs.Push(v.removeSockets())
+	errs.Push(v.closePlugins())
+	log.Printf("Vroomy: Shutdown completed in %v", time.Since(start))
+	return errs.Err()
+}
+
+// IsReady will return whether or not the service is listening and accepting requests
+// Note: Readiness is flipped to false as soon as a shutdown begins
+func (v *Vroomy) IsReady() bool {
+	return v.ready.Get()
+}
+
+func (v *Vroomy) shutdownServers(ctx context.Context) (err error) {
+	v.mu.RLock()
+	servers := v.servers
+	v.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		emu  sync.Mutex
+		errs errors.ErrorList
+	)
+
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(srv *http.Server) {
+			defer wg.Done()
+			err := srv.Shutdown(ctx)
+			emu.Lock()
+			defer emu.Unlock()
+			errs.Push(err)
+		}(srv)
+	}
+
+	wg.Wait()
+	return errs.Err()
+}
+
+// getCloseOrder will return the plugin keys in reverse dependency order, so plugins are closed before their dependencies
+func (v *Vroomy) getCloseOrder() (order []string) {
+	var err error
+	if order, err = v.getDependencies().loadOrder(); err != nil {
+		// Dependencies are validated before plugins are loaded, fall back to sorted keys
+		order = make([]string, 0, len(v.pm))
+		for key := range v.pm {
+			order = append(order, key)
+		}
+
+		sort.Strings(order)
+	}
+
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return
+}
+
+func (v *Vroomy) closePlugin(key string, p Plugin) (err error) {
+	start := time.Now()
+	if err = p.Close(); err != nil {
+		v.setPluginStatus(key, PluginStateFailed, err)
+		return fmt.Errorf("error closing <%s>: %v", getPluginLabel(key, p), err)
+	}
+
+	v.setPluginStatus(key, PluginStateClosed, nil)
+	log.Printf("Vroomy: Closed %s in %v", getPluginLabel(key, p), time.Since(start))
+	return
+}
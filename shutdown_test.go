@@ -0,0 +1,149 @@
+package vroomy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vroomy/httpserve"
+)
+
+type slowPlugin struct {
+	BasePlugin
+
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowPlugin) Slow(ctx *httpserve.Context) {
+	close(s.started)
+	<-s.release
+	ctx.WriteString(200, "text/plain", "done")
+}
+
+type closeOrderPlugin struct {
+	BasePlugin
+
+	key    string
+	closed *[]string
+}
+
+func (c *closeOrderPlugin) Backend() interface{} {
+	return c
+}
+
+func (c *closeOrderPlugin) Close() error {
+	*c.closed = append(*c.closed, c.key)
+	return nil
+}
+
+type dependentCloseOrderPlugin struct {
+	closeOrderPlugin
+
+	Dependency *closeOrderPlugin `vroomy:"dependency"`
+}
+
+func TestVroomy_Shutdown(t *testing.T) {
+	var closed []string
+	sp := &slowPlugin{started: make(chan struct{}), release: make(chan struct{})}
+	r := NewRegistry()
+	if err := r.Register("slow", sp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("dependent", &dependentCloseOrderPlugin{closeOrderPlugin: closeOrderPlugin{key: "dependent", closed: &closed}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("dependency", &closeOrderPlugin{key: "dependency", closed: &closed}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(newTestConfig(t, "slow.Slow"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := serveTestListener(t, v)
+	v.ready.Set(true)
+
+	respC := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(url + "/hello")
+		if err != nil {
+			t.Error(err)
+		}
+
+		respC <- resp
+	}()
+
+	<-sp.started
+	shutdownC := make(chan error, 1)
+	go func() {
+		shutdownC <- v.Shutdown(context.Background())
+	}()
+
+	// Allow shutdown to begin before releasing the in-flight request
+	time.Sleep(time.Millisecond * 50)
+	if v.IsReady() {
+		t.Fatal("invalid ready state, expected false during shutdown and received true")
+	}
+
+	close(sp.release)
+	if resp := <-respC; resp == nil || resp.StatusCode != 200 {
+		t.Fatalf("invalid response, expected status code of 200 and received %v", resp)
+	}
+
+	if err = <-shutdownC; err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"dependent", "dependency"}; !stringSliceEqual(want, closed) {
+		t.Fatalf("invalid close order, expected %v and received %v", want, closed)
+	}
+}
+
+func TestVroomy_Shutdown_timeout(t *testing.T) {
+	sp := &slowPlugin{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(sp.release)
+
+	r := NewRegistry()
+	if err := r.Register("slow", sp); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t, "slow.Slow")
+	cfg.ShutdownTimeout = time.Millisecond * 50
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := serveTestListener(t, v)
+	go http.Get(url + "/hello")
+	<-sp.started
+
+	start := time.Now()
+	if err = v.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("invalid shutdown duration, expected shutdown timeout to be respected and received %v", elapsed)
+	}
+}
+
+// serveTestListener will serve the router of the provided service on a random local port
+func serveTestListener(t *testing.T, v *Vroomy) (url string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newHTTPServer(&v.handler, "", v.cfg)
+	v.servers = append(v.servers, srv)
+	go srv.Serve(l)
+	return "http://" + l.Addr().String()
+}
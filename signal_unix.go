@@ -0,0 +1,11 @@
+//go:build !windows
+
+package vroomy
+
+import (
+	"os"
+	"syscall"
+)
+
+// upgradeSignals will trigger the listeners to be handed off to a new process
+var upgradeSignals = []os.Signal{syscall.SIGUSR2}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package vroomy
+
+import "os"
+
+// upgradeSignals will trigger the listeners to be handed off to a new process
+// Note: Listener handoff is not supported on Windows
+var upgradeSignals []os.Signal
@@ -0,0 +1,44 @@
+package vroomy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// staleSocketTimeout is how long to wait when checking whether a socket is still accepting connections
+const staleSocketTimeout = time.Second
+
+// removeStaleSocket will remove a socket file left by a previous process which is no longer accepting connections
+// Note: Files which are not sockets are left in place, binding to them will fail
+func removeStaleSocket(loc string) (err error) {
+	var info os.FileInfo
+	info, err = os.Lstat(loc)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("error checking socket <%s>: %v", loc, err)
+	case info.Mode()&os.ModeSocket == 0:
+		return
+	}
+
+	var conn net.Conn
+	if conn, err = net.DialTimeout(networkUnix, loc, staleSocketTimeout); err == nil {
+		conn.Close()
+		return fmt.Errorf("error binding socket <%s>: address in use", loc)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) && !errors.Is(err, syscall.ENOENT) {
+		return fmt.Errorf("error checking socket <%s>: %v", loc, err)
+	}
+
+	if err = os.Remove(loc); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing stale socket <%s>: %v", loc, err)
+	}
+
+	return nil
+}
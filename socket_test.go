@@ -0,0 +1,57 @@
+package vroomy
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_removeStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	live := filepath.Join(dir, "live.sock")
+	ll, err := net.Listen(networkUnix, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ll.Close()
+
+	stale := filepath.Join(dir, "stale.sock")
+	sl, err := net.ListenUnix(networkUnix, &net.UnixAddr{Name: stale, Net: networkUnix})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing without unlinking leaves the socket file behind, as a process which exited would
+	sl.SetUnlinkOnClose(false)
+	sl.Close()
+
+	regular := filepath.Join(dir, "regular.sock")
+	if err = os.WriteFile(regular, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		loc         string
+		expectedErr string
+		wantExists  bool
+	}
+
+	tcs := []testcase{
+		{loc: live, expectedErr: "error binding socket <" + live + ">: address in use", wantExists: true},
+		{loc: stale, wantExists: false},
+		{loc: regular, wantExists: true},
+		{loc: filepath.Join(dir, "missing.sock"), wantExists: false},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(removeStaleSocket(tc.loc)); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+
+		if _, err = os.Lstat(tc.loc); (err == nil) != tc.wantExists {
+			t.Fatalf("invalid file state for <%s>, expected exists to be %v and received %v", tc.loc, tc.wantExists, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package vroomy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdbu/errors"
+)
+
+func newTestEnvironment(env Environment, dataDir string) (out Environment) {
+	out = make(Environment, len(env)+1)
+	for key, val := range env {
+		out[key] = val
+	}
+
+	// Always test against the throwaway data directory
+	out["dataDir"] = dataDir
+	return
+}
+
+func runTest(key string, pi Plugin) (r TestResult) {
+	r.Key = key
+	t, ok := pi.(Tester)
+	if !ok {
+		r.Skipped = true
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.Err = fmt.Errorf("panic encountered: %v", rec)
+		}
+
+		r.Duration = time.Since(start)
+	}()
+
+	r.Err = t.Test()
+	return
+}
+
+// TestResult represents the result of a single plugin self-test
+type TestResult struct {
+	// Plugin key
+	Key string `json:"key"`
+	// Skipped is true when the plugin does not implement Tester
+	Skipped bool `json:"skipped"`
+	// Duration of the test
+	Duration time.Duration `json:"duration"`
+	// Error returned by the test (if any)
+	Err error `json:"-"`
+}
+
+// String will return a formatted version of the test result
+func (t *TestResult) String() string {
+	switch {
+	case t.Skipped:
+		return fmt.Sprintf("SKIP %s (no tests)", t.Key)
+	case t.Err != nil:
+		return fmt.Sprintf("FAIL %s (%v): %v", t.Key, t.Duration, t.Err)
+	default:
+		return fmt.Sprintf("PASS %s (%v)", t.Key, t.Duration)
+	}
+}
+
+// TestResults represents the results of a test run, sorted by plugin key
+type TestResults []TestResult
+
+func (t TestResults) sort() {
+	sort.Slice(t, func(i, j int) bool {
+		return t[i].Key < t[j].Key
+	})
+}
+
+// Failed will return the number of failed tests
+func (t TestResults) Failed() (n int) {
+	for _, r := range t {
+		if r.Err != nil {
+			n++
+		}
+	}
+
+	return
+}
+
+// Err will return the aggregated error of all failed tests (if any)
+func (t TestResults) Err() (err error) {
+	var errs errors.ErrorList
+	for _, r := range t {
+		if r.Err == nil {
+			continue
+		}
+
+		errs.Push(fmt.Errorf("plugin <%s> failed test: %v", r.Key, r.Err))
+	}
+
+	return errs.Err()
+}
+
+// String will return a formatted report of the test results
+func (t TestResults) String() string {
+	var sb strings.Builder
+	for _, r := range t {
+		sb.WriteString(r.String())
+		sb.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&sb, "%d tested, %d failed", len(t), t.Failed())
+	return sb.String()
+}
+
+func makeTestDir() (dir string, err error) {
+	if dir, err = os.MkdirTemp("", "vroomy-testData-"); err != nil {
+		err = fmt.Errorf("error initializing test data directory: %v", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,224 @@
+package vroomy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdbu/errors"
+	"github.com/vroomy/httpserve"
+)
+
+const (
+	// VerifyClientRequire requires clients to provide a certificate signed by the client CA
+	VerifyClientRequire = "require"
+	// VerifyClientOptional verifies client certificates when they are provided
+	VerifyClientOptional = "optional"
+)
+
+// tlsVersions are the supported minimum TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCurves are the supported key exchange mechanisms
+var tlsCurves = []tls.CurveID{tls.X25519MLKEM768, tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+// TLSConfig is the TLS configuration of the HTTPS listeners
+type TLSConfig struct {
+	// MinVersion is the minimum TLS version ("1.0", "1.1", "1.2" or "1.3"), defaults to "1.2"
+	MinVersion string `toml:"minVersion"`
+	// CipherSuites are the names of the enabled TLS 1.0-1.2 cipher suites (e.g. "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256")
+	// Note: TLS 1.3 cipher suites are not configurable
+	CipherSuites []string `toml:"cipherSuites"`
+	// CurvePreferences are the names of the key exchange mechanisms in order of preference (e.g. "X25519" or "P256")
+	CurvePreferences []string `toml:"curvePreferences"`
+	// ALPN are the application protocols to advertise, replacing the HTTP/2 and HTTP/1.1 defaults
+	ALPN []string `toml:"alpn"`
+
+	// ClientCA is a PEM bundle of the certificate authorities used to verify client certificates
+	ClientCA string `toml:"clientCA"`
+	// VerifyClient is either "require" or "optional", defaults to "require" when a client CA is set
+	VerifyClient string `toml:"verifyClient"`
+}
+
+func (t *TLSConfig) validate(h *HTTP2Config) (err error) {
+	var errs errors.ErrorList
+	if len(t.MinVersion) > 0 {
+		_, err = parseTLSVersion(t.MinVersion)
+		errs.Push(err)
+	}
+
+	_, err = parseCipherSuites(t.CipherSuites)
+	errs.Push(err)
+	_, err = parseCurves(t.CurvePreferences)
+	errs.Push(err)
+
+	switch t.VerifyClient {
+	case "":
+	case VerifyClientRequire, VerifyClientOptional:
+		if len(t.ClientCA) == 0 {
+			errs.Push(fmt.Errorf("invalid tls config, verify client of <%s> requires a client CA", t.VerifyClient))
+		}
+
+	default:
+		errs.Push(fmt.Errorf("invalid tls config, verify client of <%s> is not supported, expected \"%s\" or \"%s\"",
+			t.VerifyClient, VerifyClientRequire, VerifyClientOptional))
+	}
+
+	if h.Disable && slices.Contains(t.ALPN, nextProtoHTTP2) {
+		errs.Push(fmt.Errorf("invalid tls config, alpn cannot contain <%s> when http2 is disabled", nextProtoHTTP2))
+	}
+
+	return errs.Err()
+}
+
+// getClientAuth will return the client authentication type
+func (t *TLSConfig) getClientAuth() tls.ClientAuthType {
+	switch {
+	case len(t.ClientCA) == 0:
+		return tls.NoClientCert
+	case t.VerifyClient == VerifyClientOptional:
+		return tls.VerifyClientCertIfGiven
+
+	default:
+		return tls.RequireAndVerifyClientCert
+	}
+}
+
+// apply will apply the settings to a TLS configuration
+// Note: ALPN protocols which have already been set for other purposes (e.g. ACME challenges) are retained
+func (t *TLSConfig) apply(cfg *tls.Config) (err error) {
+	if len(t.MinVersion) > 0 {
+		if cfg.MinVersion, err = parseTLSVersion(t.MinVersion); err != nil {
+			return
+		}
+	}
+
+	if cfg.CipherSuites, err = parseCipherSuites(t.CipherSuites); err != nil {
+		return
+	}
+
+	if cfg.CurvePreferences, err = parseCurves(t.CurvePreferences); err != nil {
+		return
+	}
+
+	if len(t.ALPN) > 0 {
+		protos := slices.DeleteFunc(slices.Clone(cfg.NextProtos), func(proto string) bool {
+			return proto == nextProtoHTTP2 || proto == nextProtoHTTP1 || slices.Contains(t.ALPN, proto)
+		})
+
+		cfg.NextProtos = append(slices.Clone(t.ALPN), protos...)
+	}
+
+	if cfg.ClientAuth = t.getClientAuth(); cfg.ClientAuth == tls.NoClientCert {
+		return
+	}
+
+	if cfg.ClientCAs, err = loadCertPool(t.ClientCA); err != nil {
+		return fmt.Errorf("error loading client CA <%s>: %v", t.ClientCA, err)
+	}
+
+	return
+}
+
+func parseTLSVersion(value string) (version uint16, err error) {
+	var ok bool
+	if version, ok = tlsVersions[strings.TrimPrefix(strings.ToUpper(value), "TLS")]; !ok {
+		return 0, fmt.Errorf("invalid tls config, min version of <%s> is not supported, expected \"1.0\", \"1.1\", \"1.2\" or \"1.3\"", value)
+	}
+
+	return
+}
+
+func parseCipherSuites(names []string) (ids []uint16, err error) {
+	for _, name := range names {
+		i := slices.IndexFunc(tls.CipherSuites(), func(cs *tls.CipherSuite) bool {
+			return cs.Name == name
+		})
+
+		if i == -1 {
+			return nil, fmt.Errorf("invalid tls config, cipher suite of <%s> is not supported", name)
+		}
+
+		ids = append(ids, tls.CipherSuites()[i].ID)
+	}
+
+	return
+}
+
+func parseCurves(names []string) (ids []tls.CurveID, err error) {
+	for _, name := range names {
+		i := slices.IndexFunc(tlsCurves, func(id tls.CurveID) bool {
+			return isCurveName(id, name)
+		})
+
+		if i == -1 {
+			return nil, fmt.Errorf("invalid tls config, curve of <%s> is not supported", name)
+		}
+
+		ids = append(ids, tlsCurves[i])
+	}
+
+	return
+}
+
+// isCurveName will return whether or not a name refers to the provided curve (e.g. "P256", "P-256" or "CurveP256")
+func isCurveName(id tls.CurveID, name string) bool {
+	idName := strings.TrimPrefix(strings.ToLower(id.String()), "curve")
+	name = strings.TrimPrefix(strings.ReplaceAll(strings.ToLower(name), "-", ""), "curve")
+	return idName == name
+}
+
+func loadCertPool(loc string) (pool *x509.CertPool, err error) {
+	var bs []byte
+	if bs, err = os.ReadFile(loc); err != nil {
+		return
+	}
+
+	pool = x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+
+	return
+}
+
+// ClientCertificate will return the verified client certificate of a request, when mutual TLS is enabled
+func ClientCertificate(ctx *httpserve.Context) (cert *x509.Certificate, ok bool) {
+	state := ctx.Request().TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return state.VerifiedChains[0][0], true
+}
+
+// ClientIdentity will return the identity of the verified client certificate of a request, which is the
+// subject common name, falling back to the first URI, DNS name or email address of the certificate
+func ClientIdentity(ctx *httpserve.Context) (identity string, ok bool) {
+	var cert *x509.Certificate
+	if cert, ok = ClientCertificate(ctx); !ok {
+		return
+	}
+
+	switch {
+	case len(cert.Subject.CommonName) > 0:
+		return cert.Subject.CommonName, true
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String(), true
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0], true
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0], true
+
+	default:
+		return "", false
+	}
+}
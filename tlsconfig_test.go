@@ -0,0 +1,247 @@
+package vroomy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vroomy/httpserve"
+)
+
+type identityPlugin struct {
+	BasePlugin
+}
+
+func (i *identityPlugin) Identity(ctx *httpserve.Context) {
+	identity, ok := ClientIdentity(ctx)
+	if !ok {
+		ctx.WriteString(http.StatusUnauthorized, "text/plain", "anonymous")
+		return
+	}
+
+	ctx.WriteString(http.StatusOK, "text/plain", identity)
+}
+
+func TestTLSConfig_validate(t *testing.T) {
+	type testcase struct {
+		cfg         TLSConfig
+		http2       HTTP2Config
+		expectedErr string
+	}
+
+	tcs := []testcase{
+		{
+			cfg: TLSConfig{
+				MinVersion:       "1.3",
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+				CurvePreferences: []string{"X25519", "P-256"},
+				ClientCA:         "ca.crt",
+				VerifyClient:     VerifyClientOptional,
+			},
+		},
+		{
+			cfg:         TLSConfig{MinVersion: "1.4"},
+			expectedErr: "invalid tls config, min version of <1.4> is not supported, expected \"1.0\", \"1.1\", \"1.2\" or \"1.3\"",
+		},
+		{
+			cfg:         TLSConfig{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			expectedErr: "invalid tls config, cipher suite of <TLS_RSA_WITH_RC4_128_SHA> is not supported",
+		},
+		{
+			cfg:         TLSConfig{CurvePreferences: []string{"P224"}},
+			expectedErr: "invalid tls config, curve of <P224> is not supported",
+		},
+		{
+			cfg:         TLSConfig{VerifyClient: VerifyClientRequire},
+			expectedErr: "invalid tls config, verify client of <require> requires a client CA",
+		},
+		{
+			cfg:         TLSConfig{ClientCA: "ca.crt", VerifyClient: "always"},
+			expectedErr: "invalid tls config, verify client of <always> is not supported, expected \"require\" or \"optional\"",
+		},
+		{
+			cfg:   TLSConfig{ALPN: []string{"http/1.1"}},
+			http2: HTTP2Config{Disable: true},
+		},
+		{
+			cfg:         TLSConfig{ALPN: []string{"h2", "http/1.1"}},
+			http2:       HTTP2Config{Disable: true},
+			expectedErr: "invalid tls config, alpn cannot contain <h2> when http2 is disabled",
+		},
+	}
+
+	for _, tc := range tcs {
+		if errStr := getErrorString(tc.cfg.validate(&tc.http2)); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		}
+	}
+}
+
+func TestTLSConfig_apply(t *testing.T) {
+	cfg := tls.Config{NextProtos: []string{"h2", "http/1.1", "acme-tls/1"}}
+	tc := TLSConfig{
+		MinVersion:       "1.3",
+		CurvePreferences: []string{"x25519", "CurveP384"},
+		ALPN:             []string{"http/1.1"},
+	}
+
+	if err := tc.apply(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("invalid min version, expected %s and received %s", tls.VersionName(tls.VersionTLS13), tls.VersionName(cfg.MinVersion))
+	}
+
+	if expected := []tls.CurveID{tls.X25519, tls.CurveP384}; !slices.Equal(cfg.CurvePreferences, expected) {
+		t.Fatalf("invalid curve preferences, expected %v and received %v", expected, cfg.CurvePreferences)
+	}
+
+	if expected := []string{"http/1.1", "acme-tls/1"}; !slices.Equal(cfg.NextProtos, expected) {
+		t.Fatalf("invalid protocols, expected %v and received %v", expected, cfg.NextProtos)
+	}
+
+	if cfg.ClientAuth != tls.NoClientCert {
+		t.Fatalf("invalid client auth, expected %v and received %v", tls.NoClientCert, cfg.ClientAuth)
+	}
+}
+
+func TestVroomy_Listen_mutualTLS(t *testing.T) {
+	type testcase struct {
+		verifyClient     string
+		withCertificate  bool
+		expectedStatus   int
+		expectedIdentity string
+		expectedErr      bool
+	}
+
+	tcs := []testcase{
+		{verifyClient: VerifyClientRequire, withCertificate: true, expectedStatus: http.StatusOK, expectedIdentity: "client-a"},
+		{verifyClient: VerifyClientRequire, expectedErr: true},
+		{verifyClient: VerifyClientOptional, withCertificate: true, expectedStatus: http.StatusOK, expectedIdentity: "client-a"},
+		{verifyClient: VerifyClientOptional, expectedStatus: http.StatusUnauthorized, expectedIdentity: "anonymous"},
+	}
+
+	dir := t.TempDir()
+	writeTestCertificate(t, dir, "localhost")
+	ca, caKey := newTestCA(t)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", ca.Raw)
+	clientCert := newTestClientCertificate(t, ca, caKey, "client-a")
+
+	for _, tc := range tcs {
+		r := NewRegistry()
+		if err := r.Register("i", &identityPlugin{}); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := newTestConfig(t, "i.Identity")
+		cfg.TLSListen = "127.0.0.1:0"
+		cfg.TLSDir = dir
+		cfg.TLS = TLSConfig{ClientCA: filepath.Join(dir, "ca.pem"), VerifyClient: tc.verifyClient}
+
+		v, err := NewWithRegistry(cfg, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go v.Listen(ctx)
+		awaitReady(t, v)
+
+		tlsCfg := &tls.Config{InsecureSkipVerify: true}
+		if tc.withCertificate {
+			tlsCfg.Certificates = []tls.Certificate{clientCert}
+		}
+
+		client := http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+		resp, err := client.Get("https://" + v.Addrs()[ListenerHTTPS].String() + "/hello")
+		switch {
+		case tc.expectedErr && err == nil:
+			resp.Body.Close()
+			t.Fatalf("invalid response for <%s>, expected handshake error and received %d", tc.verifyClient, resp.StatusCode)
+		case tc.expectedErr:
+		case err != nil:
+			t.Fatal(err)
+
+		default:
+			bs, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Fatalf("invalid status code for <%s>, expected %d and received %d", tc.verifyClient, tc.expectedStatus, resp.StatusCode)
+			}
+
+			if identity := string(bs); identity != tc.expectedIdentity {
+				t.Fatalf("invalid identity for <%s>, expected <%s> and received <%s>", tc.verifyClient, tc.expectedIdentity, identity)
+			}
+		}
+
+		cancel()
+		v.Close()
+	}
+}
+
+func newTestCA(t *testing.T) (ca *x509.Certificate, key *ecdsa.PrivateKey) {
+	var err error
+	if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ca, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func newTestClientCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, name string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
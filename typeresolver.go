@@ -0,0 +1,83 @@
+package vroomy
+
+import (
+	"reflect"
+	"sort"
+)
+
+var autoWireType = reflect.TypeOf(AutoWire{})
+
+// AutoWire can be embedded within a plugin to opt-in to resolving every untagged,
+// exported interface field by type (as if the field was tagged with `vroomy:",auto"`)
+// Note: Types are resolved before plugins are loaded, backends must be set by the time Init has been called
+type AutoWire struct{}
+
+func newTypeResolver(backends map[string]interface{}) *typeResolver {
+	var t typeResolver
+	t.backends = make(map[string]reflect.Type, len(backends))
+	for key, backend := range backends {
+		if backend == nil {
+			// Plugins without a backend cannot be resolved by type
+			continue
+		}
+
+		t.backends[key] = reflect.TypeOf(backend)
+	}
+
+	return &t
+}
+
+// typeResolver resolves plugin keys by the type of their backend
+type typeResolver struct {
+	backends map[string]reflect.Type
+	// Plugin key to exclude from resolution (the dependent plugin)
+	exclude string
+}
+
+// Without will return a copy of the resolver which excludes the provided plugin key
+func (t *typeResolver) Without(key string) *typeResolver {
+	if t == nil {
+		return nil
+	}
+
+	out := *t
+	out.exclude = key
+	return &out
+}
+
+// Matches will return the sorted keys of every plugin whose backend can be set to the provided type
+func (t *typeResolver) Matches(fieldType reflect.Type) (keys []string) {
+	if t == nil {
+		return
+	}
+
+	for key, backendType := range t.backends {
+		if key == t.exclude {
+			continue
+		}
+
+		if !isSettableType(fieldType, backendType) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+func hasAutoWire(rtype reflect.Type) bool {
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		if field.Anonymous && field.Type == autoWireType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAutoWireable(field reflect.StructField) bool {
+	return field.IsExported() && field.Type.Kind() == reflect.Interface
+}
@@ -11,10 +11,6 @@ import (
 	"golang.org/x/crypto/acme/autocert"
 )
 
-type listener interface {
-	Listen(port uint16) error
-}
-
 func initDir(loc string) (err error) {
 	if len(loc) == 0 {
 		return
@@ -49,7 +45,7 @@ func getHandlerParts(handlerKey string) (key, handler string, args []string, err
 	handler = spl[0]
 	argsStr := spl[1]
 
-	if argsStr[len(argsStr)-1] != ')' {
+	if len(argsStr) == 0 || argsStr[len(argsStr)-1] != ')' {
 		err = ErrExpectedEndParen
 		return
 	}
@@ -59,25 +55,27 @@ func getHandlerParts(handlerKey string) (key, handler string, args []string, err
 	return
 }
 
-func getHostPolicy() (hp autocert.HostPolicy, err error) {
-	var method interface{}
-	method, err = getPluginMethod("autocert", "HostPolicy")
-	switch {
-	case err == nil:
-		return assertAsHostPolicy(method)
-	case isUnregisteredPluginError(err):
-		return nil, nil
-	default:
-		return
+func validateHandlers(handlerKeys []string) (err error) {
+	for _, handlerKey := range handlerKeys {
+		if _, _, _, err = getHandlerParts(handlerKey); err != nil {
+			return
+		}
 	}
+
+	return
 }
 
-func getPluginMethod(pluginKey, method string) (out interface{}, err error) {
-	var plugin Plugin
-	if plugin, err = p.Get(pluginKey); err != nil {
-		return
+func isSupportedMethod(method string) bool {
+	switch strings.ToLower(method) {
+	case "", "get", "put", "post", "delete", "options":
+		return true
+
+	default:
+		return false
 	}
+}
 
+func getPluginMethod(plugin Plugin, pluginKey, method string) (out interface{}, err error) {
 	reflected := reflect.ValueOf(plugin).MethodByName(method)
 	if reflected.Kind() == reflect.Invalid {
 		err = fmt.Errorf("method of <%s> not found within plugin <%s>", method, pluginKey)
@@ -88,22 +86,7 @@ func getPluginMethod(pluginKey, method string) (out interface{}, err error) {
 	return
 }
 
-func getHandler(handlerKey string) (h httpserve.Handler, err error) {
-	var (
-		key     string
-		handler string
-		args    []string
-	)
-
-	if key, handler, args, err = getHandlerParts(handlerKey); err != nil {
-		return
-	}
-
-	var toAssert interface{}
-	if toAssert, err = getPluginMethod(key, handler); err != nil {
-		return
-	}
-
+func assertAsHandler(toAssert interface{}, args []string) (h httpserve.Handler, err error) {
 	switch val := toAssert.(type) {
 	case func(*httpserve.Context):
 		h = val
@@ -119,18 +102,26 @@ func getHandler(handlerKey string) (h httpserve.Handler, err error) {
 }
 
 func canSet(a, b reflect.Value) (err error) {
+	if !isSettableType(a.Type(), b.Type()) {
+		// The provided value isn't an exact match, nor does it match the provided interface
+		return fmt.Errorf("invalid type, expected %v and received %v", a.Type(), b.Type())
+	}
+
+	return
+}
+
+func isSettableType(a, b reflect.Type) bool {
 	switch {
 	// Check to see if the types match exactly
-	case a.Type() == b.Type():
+	case a == b:
+		return true
 	// Check to see if the backend type implements the provided interface
-	case a.Kind() == reflect.Interface && b.Type().Implements(a.Type()):
+	case a.Kind() == reflect.Interface && b.Implements(a):
+		return true
 
 	default:
-		// The provided value isn't an exact match, nor does it match the provided interface
-		return fmt.Errorf("invalid type, expected %v and received %v", a.Type(), b.Type())
+		return false
 	}
-
-	return
 }
 
 func copySlice[T any](in []T) (out []T) {
@@ -2,19 +2,26 @@ package vroomy
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"maps"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"reflect"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gdbu/atoms"
 	"github.com/gdbu/errors"
+	"github.com/gdbu/queue"
 	"github.com/vroomy/httpserve"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 const (
@@ -45,20 +52,28 @@ func New(configLocation string) (sp *Vroomy, err error) {
 		return
 	}
 
-	if _, ok := cfg.Environment["dataDir"]; !ok {
-		// Default if not set elsewhere
-		cfg.Environment["dataDir"] = "data"
-	}
-
+	cfg.setDefaultDataDir()
 	return NewWithConfig(cfg)
 }
 
 // NewWithConfig will return a new instance of service with a provided config
+// Note: Plugins are sourced from the default registry
 func NewWithConfig(cfg *Config) (vp *Vroomy, err error) {
+	return NewWithRegistry(cfg, defaultRegistry)
+}
+
+// NewWithRegistry will return a new instance of service with a provided config and plugin registry
+func NewWithRegistry(cfg *Config, r *Registry) (vp *Vroomy, err error) {
+	// Note: Routes and groups are checked as they are initialized rather than by Validate, unsupported methods are served as GET
+	if err = cfg.validateSettings(); err != nil {
+		err = fmt.Errorf("error validating config: %v", err)
+		return
+	}
+
+	cfg.setDefaultListenerNames()
 	var v Vroomy
 	v.cfg = cfg
-	if err = os.Chdir(v.cfg.Dir); err != nil {
-		err = fmt.Errorf("error changing directory: %v", err)
+	if err = v.initDir(); err != nil {
 		return
 	}
 
@@ -67,9 +82,9 @@ func NewWithConfig(cfg *Config) (vp *Vroomy, err error) {
 		return
 	}
 
-	v.srv = httpserve.New()
+	v.srv = newRouter()
 	v.srv.SetOnError(v.cfg.ErrorLogger)
-	v.pm = p.Loaded()
+	v.pm = r.Loaded()
 
 	if err = v.initPlugins(); err != nil {
 		return
@@ -79,52 +94,193 @@ func NewWithConfig(cfg *Config) (vp *Vroomy, err error) {
 		return
 	}
 
-	if err = v.initGroups(); err != nil {
+	if err = v.initGroups(v.cfg, v.srv); err != nil {
 		err = fmt.Errorf("error initializing groups: %v", err)
 		return
 	}
 
-	if err = v.initRoutes(); err != nil {
+	if err = v.initRoutes(v.cfg, v.srv); err != nil {
 		err = fmt.Errorf("error initializing routes: %v", err)
 		return
 	}
 
+	var adminSrv *router
+	if adminSrv, err = v.initAdmin(v.cfg); err != nil {
+		return
+	}
+
+	v.handler.set(v.srv)
+	if adminSrv != nil {
+		v.admin.set(adminSrv)
+	}
+
 	vp = &v
 	return
 }
 
+func (v *Vroomy) initDir() (err error) {
+	if !v.cfg.ChangeDir {
+		// Resolve relative paths against the service directory rather than changing the working directory
+		if err = v.cfg.resolvePaths(); err != nil {
+			err = fmt.Errorf("error resolving paths: %v", err)
+		}
+
+		return
+	}
+
+	// Legacy behavior, change the working directory of the process to the service directory
+	if err = os.Chdir(v.cfg.Dir); err != nil {
+		err = fmt.Errorf("error changing directory: %v", err)
+		return
+	}
+
+	return
+}
+
 // Vroomy manages the web service
 type Vroomy struct {
 	cfg *Config
-	srv *httpserve.Serve
+	srv *router
 
 	pm map[string]Plugin
 
+	// Lifecycle status of each plugin
+	mu     sync.RWMutex
+	status map[string]pluginStatus
+	// Backends of each loaded plugin
+	backends map[string]interface{}
+	// Dependencies of each plugin, set when the plugins are loaded
+	dependencies dependenciesMap
+	// Routes registered by plugins through the router service
+	routes []pluginRoute
+
+	// Handler which serves requests using the current router
+	handler routerHandler
+	// Handler which serves admin requests using the current admin router
+	admin routerHandler
+	// Servers which have been started by Listen
+	servers []*http.Server
+	// Listeners which are being served, by name
+	listeners map[string]net.Listener
+	// Listeners which have been provided by SetListener, by name
+	provided map[string]net.Listener
+	// Certificate stores of TLS directories, by directory
+	certificates map[string]*certificateStore
+	// Socket files of Unix domain socket listeners
+	sockets []string
+	// stop will stop listening, it is set by Listen
+	stop context.CancelFunc
+
+	// Ensures only one reload (or plugin route registration) occurs at a time
+	reloadMu sync.Mutex
+
+	// Ready state, set once listening and unset when shutting down
+	ready atoms.Bool
+	// readyC is closed once all listeners are bound and accepting connections
+	readyC chan struct{}
+	// Upgrading state, set while listeners are being handed off
+	upgrading atoms.Bool
+	// Handed off state, set once listeners have been handed off to a new process
+	handedOff atoms.Bool
 	// Closed state
 	closed atoms.Bool
 }
 
+// Plugins will return the introspected information for each plugin, sorted by key
+func (v *Vroomy) Plugins() (infos []PluginInfo) {
+	dms := v.getDependencies()
+	statuses := v.getPluginStatuses()
+	// Note: Plugin info is created without holding the lock, as Describe is plugin code
+	infos = make([]PluginInfo, 0, len(v.pm))
+	for key, pi := range v.pm {
+		infos = append(infos, newPluginInfo(key, pi, dms[key], statuses[key]))
+	}
+
+	sortPluginInfos(infos)
+	return
+}
+
+// DependencyGraph will return the plugin dependency graph
+// Note: When a circular import exists, the graph is returned along with a *CycleError
+func (v *Vroomy) DependencyGraph() (g *Graph, err error) {
+	return newGraph(v.getDependencies())
+}
+
+// getDependencies will return the dependencies map created when the plugins were loaded
+func (v *Vroomy) getDependencies() (dms dependenciesMap) {
+	v.mu.RLock()
+	dms = v.dependencies
+	v.mu.RUnlock()
+	if dms != nil {
+		return
+	}
+
+	// Plugins have not been loaded, create the dependencies map from the current backends
+	return v.makeDependenciesMap()
+}
+
+func (v *Vroomy) setDependenciesMap(dms dependenciesMap) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.dependencies = dms
+}
+
+func (v *Vroomy) getPluginStatuses() (statuses map[string]pluginStatus) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return maps.Clone(v.status)
+}
+
+func (v *Vroomy) getPluginState(key string) (state PluginState) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.status[key].state
+}
+
+func (v *Vroomy) setPluginStatus(key string, state PluginState, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.status == nil {
+		v.status = make(map[string]pluginStatus, len(v.pm))
+	}
+
+	status := v.status[key]
+	status.state = state
+	status.err = err
+	v.status[key] = status
+}
+
+func (v *Vroomy) setPluginLoadDuration(key string, duration time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	status := v.status[key]
+	status.loadDuration = duration
+	v.status[key] = status
+}
+
 func (v *Vroomy) initPlugins() (err error) {
 	// Call Init(flags, env) for each initialized plugin
 	for pluginKey, plugin := range v.pm {
 		if err = plugin.Init(v.cfg.Environment); err != nil {
-			err = fmt.Errorf("error loading plugin <%s>: %v", pluginKey, err)
+			v.setPluginStatus(pluginKey, PluginStateFailed, err)
+			err = fmt.Errorf("error loading plugin <%s>: %v", getPluginLabel(pluginKey, plugin), err)
 			return
 		}
 
-		log.Printf("Vroomy: Initialized %s\n", pluginKey)
+		v.setPluginStatus(pluginKey, PluginStateInitialized, nil)
+		log.Printf("Vroomy: Initialized %s\n", getPluginLabel(pluginKey, plugin))
 	}
 
 	return
 }
 
-func (v *Vroomy) initGroups() (err error) {
-	if len(v.cfg.Groups) == 0 {
+func (v *Vroomy) initGroups(cfg *Config, srv *router) (err error) {
+	if len(cfg.Groups) == 0 {
 		return
 	}
 
 	//filter, ok := v.cfg.Flags["require"]
-	for _, group := range v.cfg.Groups {
+	for _, group := range cfg.Groups {
 		// TODO: Document what this does and uncomment
 		//if ok {
 		//	var hasPlugin = false
@@ -140,7 +296,7 @@ func (v *Vroomy) initGroups() (err error) {
 		//	}
 		//}
 
-		if err = v.initRouteGroup(group); err != nil {
+		if err = v.initRouteGroup(cfg, srv, group); err != nil {
 			return
 		}
 	}
@@ -148,10 +304,15 @@ func (v *Vroomy) initGroups() (err error) {
 	return
 }
 
-func (v *Vroomy) initRouteGroup(g *RouteGroup) (err error) {
+func (v *Vroomy) initRouteGroup(cfg *Config, srv *router, g *RouteGroup) (err error) {
+	if h := newLimitsHandler(g.Timeout, g.MaxBodySize); h != nil {
+		// Limits are applied before the group handlers
+		g.HTTPHandlers = append(g.HTTPHandlers, h)
+	}
+
 	for _, handlerKey := range g.Handlers {
 		var h httpserve.Handler
-		if h, err = getHandler(handlerKey); err != nil {
+		if h, err = v.getHandler(handlerKey); err != nil {
 			err = fmt.Errorf("initRouteGroup(): error getting handler for key of <%s>: %v", handlerKey, err)
 			return
 		}
@@ -161,10 +322,10 @@ func (v *Vroomy) initRouteGroup(g *RouteGroup) (err error) {
 
 	var (
 		match *RouteGroup
-		grp   httpserve.Group = v.srv
+		grp   httpserve.Group = srv
 	)
 
-	if match, err = v.cfg.GetRouteGroup(g.Group); err != nil {
+	if match, err = cfg.GetRouteGroup(g.Group); err != nil {
 		return
 	} else if match != nil {
 		if grp = match.G; grp == nil {
@@ -177,12 +338,12 @@ func (v *Vroomy) initRouteGroup(g *RouteGroup) (err error) {
 	return
 }
 
-func (v *Vroomy) initRoutes() (err error) {
+func (v *Vroomy) initRoutes(cfg *Config, srv *router) (err error) {
 	// Set panic func
-	v.srv.SetPanic(v.handlePanic)
+	srv.SetPanic(v.handlePanic)
 
 	//filter, ok := v.cfg.Flags["require"]
-	for _, r := range v.cfg.Routes {
+	for _, r := range cfg.Routes {
 		// TODO: Document what this does and uncomment
 		//if ok {
 		//	hasPlugin := true
@@ -204,14 +365,14 @@ func (v *Vroomy) initRoutes() (err error) {
 
 		var (
 			match *RouteGroup
-			grp   httpserve.Group = v.srv
+			grp   httpserve.Group = srv
 		)
 
-		if match, err = v.cfg.GetRouteGroup(r.Group); err != nil {
+		if match, err = cfg.GetRouteGroup(r.Group); err != nil {
 			return
 		} else if match != nil {
 			if match.G == nil {
-				if err = v.initRouteGroup(match); err != nil {
+				if err = v.initRouteGroup(cfg, srv, match); err != nil {
 					return
 				}
 			}
@@ -244,9 +405,14 @@ func (v *Vroomy) initRoutes() (err error) {
 }
 
 func (v *Vroomy) initRoute(r *Route) (err error) {
+	if h := newLimitsHandler(r.Timeout, r.MaxBodySize); h != nil {
+		// Limits are applied before the route handlers
+		r.HTTPHandlers = append(r.HTTPHandlers, h)
+	}
+
 	for _, handlerKey := range r.Handlers {
 		var h httpserve.Handler
-		if h, err = getHandler(handlerKey); err != nil {
+		if h, err = v.getHandler(handlerKey); err != nil {
 			err = fmt.Errorf("initRoute(): error getting handler for key of <%s>: %v", handlerKey, err)
 			return
 		}
@@ -278,9 +444,9 @@ func (v *Vroomy) setDependencies(pluginKey string, dm dependencyMap) (err error)
 		rval = rval.Elem()
 	}
 
-	for depKey, indices := range dm {
-		field := getField(rval, indices)
-		if err = v.setBackend(field, depKey); err != nil {
+	for _, dep := range dm {
+		field := getField(rval, dep.indices)
+		if err = v.setDependency(pluginKey, field, dep.key, dep); err != nil {
 			return
 		}
 	}
@@ -288,6 +454,121 @@ func (v *Vroomy) setDependencies(pluginKey string, dm dependencyMap) (err error)
 	return pi.Load(v.cfg.Environment)
 }
 
+func (v *Vroomy) setDependency(pluginKey string, field reflect.Value, key string, dep dependency) (err error) {
+	_, isRegistered := v.pm[key]
+	switch {
+	case dep.reserved:
+		return v.setService(pluginKey, field, key)
+	case dep.collection:
+		return v.setCollection(field, dep)
+	case dep.lazy:
+		return v.setLazy(field, key)
+	case dep.optional && !isRegistered:
+		// Optional dependency has not been registered, leave the field unset
+		return
+	default:
+		return v.setBackend(field, key)
+	}
+}
+
+func (v *Vroomy) setService(pluginKey string, field reflect.Value, key string) (err error) {
+	elem := field.Elem()
+	if !elem.CanSet() {
+		return ErrNotAddressable
+	}
+
+	var service interface{}
+	if service, err = v.getService(pluginKey, key); err != nil {
+		return
+	}
+
+	beVal := reflect.ValueOf(service)
+	if err = canSet(elem, beVal); err != nil {
+		return
+	}
+
+	elem.Set(beVal)
+	return
+}
+
+func (v *Vroomy) getService(pluginKey, key string) (service interface{}, err error) {
+	switch key {
+	case ServiceEnv:
+		return Environment(v.cfg.Environment), nil
+	case ServiceLogger:
+		return log.New(log.Writer(), fmt.Sprintf("%s: ", pluginKey), log.Flags()), nil
+	case ServiceConfig:
+		return v.cfg, nil
+	case ServiceDataDir:
+		return v.cfg.Environment["dataDir"], nil
+	case ServiceRouter:
+		if v.srv == nil {
+			return nil, fmt.Errorf("cannot provide <%s> to plugin <%s>, router has not been initialized", key, pluginKey)
+		}
+
+		return &routerGroup{v: v}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid reserved dependency <%s>", key)
+	}
+}
+
+func (v *Vroomy) setCollection(field reflect.Value, dep dependency) (err error) {
+	elem := field.Elem()
+	if !elem.CanSet() {
+		return ErrNotAddressable
+	}
+
+	var collection reflect.Value
+	isMap := elem.Kind() == reflect.Map
+	if isMap {
+		collection = reflect.MakeMapWithSize(elem.Type(), len(dep.members))
+	} else {
+		collection = reflect.MakeSlice(elem.Type(), 0, len(dep.members))
+	}
+
+	target := reflect.New(elem.Type().Elem()).Elem()
+	for _, key := range dep.members {
+		var reference interface{}
+		if reference, err = v.getReference(key); err != nil {
+			return
+		}
+
+		beVal := reflect.ValueOf(reference)
+		if err = canSet(target, beVal); err != nil {
+			return fmt.Errorf("error setting collection member <%s>: %v", key, err)
+		}
+
+		if isMap {
+			collection.SetMapIndex(reflect.ValueOf(key).Convert(elem.Type().Key()), beVal)
+			continue
+		}
+
+		collection = reflect.Append(collection, beVal)
+	}
+
+	elem.Set(collection)
+	return
+}
+
+func (v *Vroomy) setLazy(field reflect.Value, key string) (err error) {
+	lr, ok := field.Interface().(lazyResolver)
+	if !ok {
+		return fmt.Errorf("invalid field type for lazy dependency <%s>, expected vroomy.Lazy and received %v", key, field.Type())
+	}
+
+	lr.setResolver(key, func() (reference interface{}, err error) {
+		if state := v.getPluginState(key); state != PluginStateLoaded {
+			err = fmt.Errorf("cannot resolve lazy dependency <%s>, plugin is %v", key, state)
+			return
+		}
+
+		return v.getReference(key)
+	})
+
+	return
+}
+
 func (v *Vroomy) getPlugin(key string) (pi Plugin, err error) {
 	var ok bool
 	if pi, ok = v.pm[key]; !ok {
@@ -304,8 +585,13 @@ func (v *Vroomy) getReference(key string) (reference interface{}, err error) {
 		return
 	}
 
-	if reference = pi.Backend(); reference == nil {
-		// The provided value isn't an exact match, nor does it match the provided interface
+	var ok bool
+	if reference, ok = v.getCachedBackend(key); !ok {
+		// Backend has not been cached yet (plugin has not been loaded), call directly
+		reference = pi.Backend()
+	}
+
+	if reference == nil {
 		err = fmt.Errorf("cannot call backend for plugin <%s>, provided value is nil", key)
 		return
 	}
@@ -313,6 +599,51 @@ func (v *Vroomy) getReference(key string) (reference interface{}, err error) {
 	return
 }
 
+// makeDependenciesMap will create the dependencies map of the plugins
+// Note: Backends are only called before the plugins are loaded when a dependency is resolved by type
+func (v *Vroomy) makeDependenciesMap() dependenciesMap {
+	var backends map[string]interface{}
+	if hasTypedDependencies(v.pm) {
+		backends = v.getBackends()
+	}
+
+	return makeDependenciesMapWithBackends(v.pm, backends)
+}
+
+// getBackends will return the backend of each plugin, using the cached backends of loaded plugins
+func (v *Vroomy) getBackends() (backends map[string]interface{}) {
+	backends = make(map[string]interface{}, len(v.pm))
+	for key, pi := range v.pm {
+		var ok bool
+		if backends[key], ok = v.getCachedBackend(key); !ok {
+			// Backend has not been cached yet (plugin has not been loaded), call directly
+			backends[key] = pi.Backend()
+		}
+	}
+
+	return
+}
+
+func (v *Vroomy) getCachedBackend(key string) (backend interface{}, ok bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	backend, ok = v.backends[key]
+	return
+}
+
+// cacheBackend will cache the backend of a plugin, called once the plugin has been loaded
+func (v *Vroomy) cacheBackend(key string) (backend interface{}) {
+	backend = v.pm[key].Backend()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.backends == nil {
+		v.backends = make(map[string]interface{}, len(v.pm))
+	}
+
+	v.backends[key] = backend
+	return
+}
+
 func (v *Vroomy) setBackend(backend reflect.Value, key string) (err error) {
 	elem := backend.Elem()
 	if !elem.CanSet() {
@@ -334,20 +665,39 @@ func (v *Vroomy) setBackend(backend reflect.Value, key string) (err error) {
 }
 
 func (v *Vroomy) loadPlugins() (err error) {
-	dms := makeDependenciesMap(v.pm)
+	// Note: The dependencies map is created once, so plugin backends are not called again for introspection
+	dms := v.makeDependenciesMap()
+	v.setDependenciesMap(dms)
+	typed := dms.hasTyped()
 	if err = dms.Validate(); err != nil {
 		return
 	}
 
 	var count int
 	if err = dms.Load(func(pluginKey string, dm dependencyMap) (err error) {
+		label := getPluginLabel(pluginKey, v.pm[pluginKey])
+		start := time.Now()
 		if err = v.setDependencies(pluginKey, dm); err != nil {
-			err = fmt.Errorf("error loading plugin <%s>: %v", pluginKey, err)
+			v.setPluginStatus(pluginKey, PluginStateFailed, err)
+			err = fmt.Errorf("error loading plugin <%s>: %v", label, err)
 			return
 		}
 
+		duration := time.Since(start)
+		backend := v.cacheBackend(pluginKey)
+		if typed {
+			if err = dms.validateBackend(pluginKey, backend); err != nil {
+				v.setPluginStatus(pluginKey, PluginStateFailed, err)
+				err = fmt.Errorf("error loading plugin <%s>: %v", label, err)
+				return
+			}
+		}
+
+		v.setPluginStatus(pluginKey, PluginStateLoaded, nil)
+		v.setPluginLoadDuration(pluginKey, duration)
+
 		count++
-		log.Printf("Vroomy: Loaded %s (%d/%d)\n", pluginKey, count, len(dms))
+		log.Printf("Vroomy: Loaded %s in %v (%d/%d)\n", label, duration, count, len(dms))
 		return
 	}); err != nil {
 		return
@@ -366,85 +716,357 @@ func (v *Vroomy) loadPlugins() (err error) {
 	return
 }
 
-func (v *Vroomy) getHTTPListener() (l listener) {
-	if v.cfg.TLSPort > 0 && !v.cfg.AllowNonTLS {
-		// TLS port exists, return a new upgrader pointing to the configured tls port
-		return httpserve.NewUpgrader(v.cfg.TLSPort)
+// getHTTPHandler will return the handler of the HTTP listener, which redirects requests to the port of the HTTPS listener
+// unless non-TLS requests are allowed
+// Note: The HTTPS listener is bound before the HTTP listener, so requests are redirected to its bound port
+func (v *Vroomy) getHTTPHandler() (h http.Handler, err error) {
+	if v.cfg.AllowNonTLS {
+		return &v.handler, nil
 	}
 
-	// TLS port does not exist, return the raw httpserve.Serve
-	return v.srv
+	v.mu.RLock()
+	nl, ok := v.listeners[ListenerHTTPS]
+	v.mu.RUnlock()
+	if !ok {
+		// HTTPS listener does not exist, return the router handler
+		return &v.handler, nil
+	}
+
+	addr, ok := nl.Addr().(*net.TCPAddr)
+	if !ok {
+		err = fmt.Errorf("cannot redirect HTTP requests to the HTTPS listener on %s, allowNonTLS must be set", describeAddress(nl.Addr()))
+		return
+	}
+
+	return newUpgradeHandler(uint16(addr.Port)), nil
+}
+
+// getListenerHandler will return the handler for the provided listener
+func (v *Vroomy) getListenerHandler(l *Listener) (h http.Handler, err error) {
+	switch l.Name {
+	case ListenerHTTP:
+		// Note: If TLS is set, an upgrade handler will be returned
+		return v.getHTTPHandler()
+	case ListenerAdmin:
+		return v.getAdminHandler(), nil
+	}
+
+	return &v.handler, nil
 }
 
-func (v *Vroomy) listenHTTP(errC chan error) {
-	if v.cfg.Port == 0 {
-		// HTTP port not set, return
+// binding is a listener which has been bound, along with the server which will serve it
+type binding struct {
+	l   *Listener
+	nl  net.Listener
+	srv *http.Server
+}
+
+// bindTo will bind (or take the provided or inherited listener for) the provided listener
+func (v *Vroomy) bindTo(l *Listener) (b *binding, err error) {
+	var (
+		network string
+		address string
+	)
+
+	// Note: Address is empty for provided listeners
+	if len(l.Address) > 0 {
+		if network, address, err = parseListenAddress(l.Address); err != nil {
+			return
+		}
+	}
+
+	var cfg *tls.Config
+	if l.IsTLS() {
+		if cfg, err = v.getTLSConfig(l); err != nil {
+			return
+		}
+
+		v.cfg.HTTP2.setTLSConfig(cfg)
+		if err = v.cfg.TLS.apply(cfg); err != nil {
+			return
+		}
+	}
+
+	var h http.Handler
+	if h, err = v.getListenerHandler(l); err != nil {
+		return
+	}
+
+	var nl net.Listener
+	if nl, err = v.listen(l, network, address); err != nil {
 		return
 	}
 
-	// Get http listener
-	// Note: If TLS is set, an httpserve.Upgrader will be returned
-	l := v.getHTTPListener()
+	b = &binding{l: l, nl: nl}
+	b.srv = newHTTPServer(h, address, v.cfg)
+	v.cfg.HTTP2.setServer(b.srv, cfg != nil)
+	if b.srv.TLSConfig = cfg; cfg != nil {
+		b.nl = tls.NewListener(nl, cfg)
+	}
+
+	return
+}
+
+// bindAll will bind all of the provided listeners, closing those already bound if any listener fails to bind
+func (v *Vroomy) bindAll(ls []*Listener) (bs []*binding, err error) {
+	for _, l := range ls {
+		var b *binding
+		if b, err = v.bindTo(l); err != nil {
+			err = fmt.Errorf("error binding <%s> listener: %v", l.Name, err)
+			v.closeBindings(bs)
+			return nil, err
+		}
+
+		bs = append(bs, b)
+	}
+
+	return
+}
+
+func (v *Vroomy) closeBindings(bs []*binding) {
+	for _, b := range bs {
+		b.nl.Close()
+		v.mu.Lock()
+		delete(v.listeners, b.l.Name)
+		v.mu.Unlock()
+	}
 
-	// Attempt to listen to HTTP with the configured port
-	errC <- l.Listen(v.cfg.Port)
+	v.removeSockets()
 }
 
-func (v *Vroomy) listenHTTPS(errC chan error) {
-	if v.cfg.TLSPort == 0 {
-		// HTTPS port not set, return
+// newTLSConfig will return a TLS configuration serving the certificates within the provided directory
+func (v *Vroomy) newTLSConfig(dir string) (cfg *tls.Config, err error) {
+	var cs *certificateStore
+	if cs, err = v.getCertificateStore(dir); err != nil {
 		return
 	}
 
+	return newTLSConfig(cs), nil
+}
+
+func (v *Vroomy) getTLSConfig(l *Listener) (cfg *tls.Config, err error) {
 	switch {
+	case len(l.TLSDir) > 0:
+		// Attempt to load the certificates within the tls directory of the listener
+		return v.newTLSConfig(l.TLSDir)
 	case v.cfg.hasTLSDir():
-		// Attempt to listen to HTTPS with the configured tls port and directory
-		errC <- v.srv.ListenTLS(v.cfg.TLSPort, v.cfg.TLSDir)
+		// Attempt to load the certificates within the configured tls directory
+		return v.newTLSConfig(v.cfg.TLSDir)
 	case v.cfg.hasAutoCert():
-		ac, err := v.cfg.autoCertConfig()
-		if err != nil {
-			errC <- err
+		var ac httpserve.AutoCertConfig
+		if ac, err = v.autoCertConfig(); err != nil {
 			return
 		}
 
-		// Attempt to listen to HTTPS with the configured tls port and directory
-		errC <- v.srv.ListenAutoCertTLS(v.cfg.TLSPort, ac)
+		return newAutoCertTLSConfig(ac), nil
+
 	default:
-		// Cannot serve TLS without a tls directory, send error down channel and return
-		errC <- ErrInvalidTLSDirectory
+		// Cannot serve TLS without a tls directory
+		return nil, ErrInvalidTLSDirectory
+	}
+}
+
+// listen will return the listener provided or inherited for the provided listener name, or bind a new listener
+func (v *Vroomy) listen(l *Listener, network, addr string) (nl net.Listener, err error) {
+	var ok, owned bool
+	if nl, ok, owned, err = v.takeListener(l.Name); err != nil {
+		err = fmt.Errorf("error inheriting <%s> listener: %v", l.Name, err)
+		return
+	} else if !ok {
+		if nl, err = v.bind(l, network, addr); err != nil {
+			return
+		}
+
+		owned = true
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.listeners == nil {
+		v.listeners = make(map[string]net.Listener)
+	}
+
+	v.listeners[l.Name] = nl
+	if ua, ok := nl.Addr().(*net.UnixAddr); ok && owned {
+		// Socket files of sockets owned by vroomy are removed once the service is closed
+		v.sockets = append(v.sockets, ua.Name)
+	}
+
+	return
+}
+
+func (v *Vroomy) bind(l *Listener, network, addr string) (nl net.Listener, err error) {
+	if network != networkUnix {
+		return net.Listen(networkTCP, addr)
+	}
+
+	mode, owner := l.SocketMode, l.SocketOwner
+	if len(mode) == 0 {
+		mode = v.cfg.SocketMode
+	}
+
+	if len(owner) == 0 {
+		owner = v.cfg.SocketOwner
+	}
+
+	return listenUnix(addr, mode, owner)
+}
+
+// removeSockets will remove the socket files of Unix domain socket listeners
+// Note: Socket files are kept when the listeners have been handed off to a new process
+func (v *Vroomy) removeSockets() (err error) {
+	if v.handedOff.Get() {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var errs errors.ErrorList
+	for _, loc := range v.sockets {
+		if err := os.Remove(loc); err != nil && !os.IsNotExist(err) {
+			errs.Push(err)
+		}
+	}
+
+	v.sockets = nil
+	return errs.Err()
+}
+
+func (v *Vroomy) closeServers() (err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var errs errors.ErrorList
+	for _, srv := range v.servers {
+		errs.Push(srv.Close())
+	}
+
+	return errs.Err()
+}
+
+func (v *Vroomy) addPluginRoute(r pluginRoute) (err error) {
+	v.reloadMu.Lock()
+	defer v.reloadMu.Unlock()
+	if err = r.register(v.getRouter()); err != nil {
 		return
+	}
+
+	v.routes = append(v.routes, r)
+	return
+}
 
+func (v *Vroomy) getRouter() (srv *router) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.srv
+}
+
+func (v *Vroomy) autoCertConfig() (ac httpserve.AutoCertConfig, err error) {
+	var primary autocert.HostPolicy
+	if primary, err = v.getHostPolicy(); err != nil {
+		return
 	}
+
+	ac = v.cfg.autoCertConfig(primary)
+	return
+}
+
+func (v *Vroomy) getHostPolicy() (hp autocert.HostPolicy, err error) {
+	var method interface{}
+	method, err = v.getPluginMethod("autocert", "HostPolicy")
+	switch {
+	case err == nil:
+		return assertAsHostPolicy(method)
+	case isUnregisteredPluginError(err):
+		return nil, nil
+	default:
+		return
+	}
+}
+
+func (v *Vroomy) getPluginMethod(pluginKey, method string) (out interface{}, err error) {
+	var plugin Plugin
+	if plugin, err = v.getPlugin(pluginKey); err != nil {
+		return
+	}
+
+	return getPluginMethod(plugin, pluginKey, method)
+}
+
+func (v *Vroomy) getHandler(handlerKey string) (h httpserve.Handler, err error) {
+	var (
+		key     string
+		handler string
+		args    []string
+	)
+
+	if key, handler, args, err = getHandlerParts(handlerKey); err != nil {
+		return
+	}
+
+	var toAssert interface{}
+	if toAssert, err = v.getPluginMethod(key, handler); err != nil {
+		return
+	}
+
+	return assertAsHandler(toAssert, args)
 }
 
 func (v *Vroomy) handlePanic(in interface{}) {
 	log.Printf("Vroomy: Panic caught:\n%v\n%s\n\n", in, string(debug.Stack()))
 }
 
-// Listen will listen to the configured port
+// Listen will bind all configured (or provided) listeners and serve them until an error occurs or the context is done
+// Bind errors (e.g. port conflicts) are returned immediately, Ready is closed once all listeners are accepting connections
 func (v *Vroomy) Listen(ctx context.Context) (err error) {
-	// Initialize error channel
-	errC := make(chan error, 2)
-	// Listen to HTTP (if needed)
-	go v.listenHTTP(errC)
-	// Listen to HTTPS (if needed)
-	go v.listenHTTPS(errC)
-
-	timer := time.NewTimer(time.Millisecond * 100)
+	// Allow listening to be stopped by the service (e.g. once listeners have been handed off to a new process)
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	v.mu.Lock()
+	v.stop = cancel
+	v.mu.Unlock()
+
+	ls := v.getListeners()
+	if err = validateSystemdListeners(getSystemdListenerNames(), ls); err != nil {
+		return
+	}
 
-	// Wait for one of the following:
-	// - Timer to end, which means we are listening succesfully
-	// - Error to come down error channel, which means an error occurred during listening
-	// - Context is finished, which means the caller no longer needing this action to continue
-	select {
-	case <-timer.C:
-		v.listenNotification()
-	case err = <-errC:
+	// Bind all listeners before serving, so bind errors (e.g. port conflicts) are returned immediately
+	var bs []*binding
+	if bs, err = v.bindAll(ls); err != nil {
 		return
-	case <-ctx.Done():
-		return ctx.Err()
 	}
 
+	v.mu.Lock()
+	if v.closed.Get() {
+		v.mu.Unlock()
+		v.closeBindings(bs)
+		return errors.ErrIsClosed
+	}
+
+	for _, b := range bs {
+		v.servers = append(v.servers, b.srv)
+	}
+	v.mu.Unlock()
+
+	// Initialize error channel
+	errC := make(chan error, len(bs))
+	// Serve each of the bound listeners
+	for _, b := range bs {
+		go func(b *binding) {
+			errC <- b.srv.Serve(b.nl)
+		}(b)
+	}
+	// Watch configuration for modifications (if enabled)
+	go v.watchConfig(ctx)
+	// Watch TLS directories for rotated certificates
+	go v.watchCertificates(ctx)
+
+	// All listeners are bound and accepting connections
+	v.setReady()
+
 	// Wait for one of the following:
 	// - Error to come down error channel, which means an error occurred during listening
 	// - Context is finished, which means the caller no longer needing this action to continue
@@ -456,29 +1078,34 @@ func (v *Vroomy) Listen(ctx context.Context) (err error) {
 	}
 }
 
-// Listen will listen to the configured port
+// ListenUntilSignal will listen to the configured port until a closing signal is received, then gracefully shutdown
+// A second closing signal received during shutdown will force the process to exit immediately
 func (v *Vroomy) ListenUntilSignal(ctx context.Context) (err error) {
 	vctx, cancel := context.WithCancel(ctx)
-	go v.onClose(cancel)
+	done := make(chan struct{})
+	defer close(done)
+	go v.onClose(cancel, done)
 
-	if err = v.Listen(vctx); err == context.Canceled {
+	if err = v.Listen(vctx); err == context.Canceled || err == http.ErrServerClosed {
 		err = nil
 	}
 
 	var errs errors.ErrorList
 	errs.Push(err)
-	errs.Push(v.Close())
+	errs.Push(v.Shutdown(context.Background()))
 	return errs.Err()
 }
 
 // Port will return the current HTTP port
+// Note: Once listening, the port of the bound (or provided) listener is returned
 func (v *Vroomy) Port() uint16 {
-	return v.cfg.Port
+	return v.getPort(ListenerHTTP, v.cfg.Port)
 }
 
 // TLSPort will return the current HTTPS port
+// Note: Once listening, the port of the bound (or provided) listener is returned
 func (v *Vroomy) TLSPort() uint16 {
-	return v.cfg.TLSPort
+	return v.getPort(ListenerHTTPS, v.cfg.TLSPort)
 }
 
 // Close will close the selected service
@@ -488,43 +1115,142 @@ func (v *Vroomy) Close() (err error) {
 	}
 
 	var errs errors.ErrorList
-	errs.Push(v.srv.Close())
-	for key, p := range v.pm {
-		if err := p.Close(); err != nil {
-			err = fmt.Errorf("error closing <%s>: %v", key, err)
-			errs.Push(err)
-		}
+	errs.Push(v.closeServers())
+	errs.Push(v.removeSockets())
+	errs.Push(v.closePlugins())
+	return errs.Err()
+}
+
+// closePlugins will close the plugins in reverse dependency order
+func (v *Vroomy) closePlugins() (err error) {
+	var errs errors.ErrorList
+	for _, key := range v.getCloseOrder() {
+		errs.Push(v.closePlugin(key, v.pm[key]))
 	}
+
 	return errs.Err()
 }
 
+// Ready will return a channel which is closed once all listeners are bound and accepting connections
+func (v *Vroomy) Ready() <-chan struct{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.readyC == nil {
+		v.readyC = make(chan struct{})
+	}
+
+	return v.readyC
+}
+
+// Addrs will return the addresses of the bound (or provided) listeners, by listener name
+func (v *Vroomy) Addrs() (addrs map[string]net.Addr) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	addrs = make(map[string]net.Addr, len(v.listeners))
+	for name, l := range v.listeners {
+		addrs[name] = l.Addr()
+	}
+
+	return
+}
+
+// setReady will flag the service as ready, notify the parent process (if any) and call the OnListen func (if set)
+func (v *Vroomy) setReady() {
+	v.ready.Set(true)
+	v.listenNotification()
+	// Notify the parent process (if any) that listeners have been inherited successfully
+	notifyParentReady()
+
+	v.mu.Lock()
+	if v.readyC == nil {
+		v.readyC = make(chan struct{})
+	}
+
+	select {
+	case <-v.readyC:
+	default:
+		close(v.readyC)
+	}
+	v.mu.Unlock()
+
+	if v.cfg.OnListen != nil {
+		v.cfg.OnListen(v.Addrs())
+	}
+}
+
 func (v *Vroomy) listenNotification() {
-	var msg string
-	switch {
-	case v.TLSPort() > 0 && v.Port() > 0:
-		msg = fmt.Sprintf("Listening on ports %d (HTTPS) and %d (HTTP)", v.TLSPort(), v.Port())
-	case v.TLSPort() > 0:
-		msg = fmt.Sprintf("Listening on port %d (HTTPS)", v.TLSPort())
-	case v.Port() > 0:
-		msg = fmt.Sprintf("Listening on port %d (HTTP)", v.Port())
+	for _, l := range v.getListeners() {
+		log.Printf("Vroomy: Listening on %s (%s)", v.describeListener(l), l.label())
+	}
+}
+
+// describeListener will return the socket path, port or address of the provided listener
+func (v *Vroomy) describeListener(l *Listener) string {
+	v.mu.RLock()
+	bound, ok := v.listeners[l.Name]
+	v.mu.RUnlock()
+	if ok {
+		return describeAddress(bound.Addr())
 	}
 
-	log.Printf("Vroomy: %v", msg)
+	return l.Address
 }
 
 // listenForClose will listen for closing signals (interrupt, terminate, abort, quit) and call close
-func (v *Vroomy) onClose(fn func()) {
+// Hangup signals will reload the configuration, upgrade signals will hand off listeners to a new process
+// and a second closing signal will exit immediately
+func (v *Vroomy) onClose(fn func(), done <-chan struct{}) {
 	// sc represents the signal channel
 	sc := make(chan os.Signal, 1)
 	// Listen for signal notifications
 	// Discussion topic: Should we include SIGQUIT? If we catch the signal, we won't get to see the unwind
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGABRT)
-	// Signal received
-	<-sc
-	fn()
+	signal.Notify(sc, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGABRT, syscall.SIGHUP}, upgradeSignals...)...)
+	defer signal.Stop(sc)
+
+	var closing bool
+	for {
+		var sig os.Signal
+		select {
+		case sig = <-sc:
+		case <-done:
+			return
+		}
+
+		switch {
+		case sig == syscall.SIGHUP:
+			// Hangup received, reload configuration and certificates
+			v.reload()
+			v.reloadCertificates()
+		case isUpgradeSignal(sig):
+			// Upgrade received, hand off listeners to a new process
+			go v.upgrade()
+		case closing:
+			// Second closing signal received, do not wait for shutdown to complete
+			log.Printf("Vroomy: Received %v during shutdown, forcing exit", sig)
+			os.Exit(1)
+
+		default:
+			// Closing signal received
+			log.Printf("Vroomy: Received %v, shutting down", sig)
+			closing = true
+			fn()
+		}
+	}
 }
 
-// Register will register a plugin with a given key
+// Register will register a plugin with a given key to the default registry
 func Register(key string, pi Plugin) error {
-	return p.Register(key, pi)
+	return defaultRegistry.Register(key, pi)
+}
+
+// Test will test all of the plugins within the default registry
+// Note: The default registry is closed once testing has completed
+func Test(env Environment) (TestResults, error) {
+	return defaultRegistry.Test(env)
+}
+
+// TestAsync will test all of the plugins within the default registry asynchronously using the provided queue
+// Note: The default registry is closed once testing has completed
+func TestAsync(q *queue.Queue, env Environment) (TestResults, error) {
+	return defaultRegistry.TestAsync(q, env)
 }
@@ -0,0 +1,561 @@
+package vroomy
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/vroomy/httpserve"
+)
+
+type handlerPlugin struct {
+	BasePlugin
+}
+
+func (h *handlerPlugin) Hello(ctx *httpserve.Context) {
+	ctx.WriteString(200, "text/plain", "hello")
+}
+
+func TestNewWithRegistry(t *testing.T) {
+	a := NewRegistry()
+	if err := a.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewRegistry()
+	if err := b.Register("b", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	va, err := NewWithRegistry(newTestConfig(t, "a.Hello"), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = va.getPlugin("b"); err == nil {
+		t.Fatal("expected error for plugin registered to another registry and received nil")
+	}
+
+	if _, err = NewWithRegistry(newTestConfig(t, "a.Hello"), b); err == nil {
+		t.Fatal("expected error for handler of plugin registered to another registry and received nil")
+	}
+
+	if _, err = NewWithRegistry(newTestConfig(t, "b.Hello"), b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestConfig(t *testing.T, handlers ...string) (cfg *Config) {
+	cfg = &Config{}
+	cfg.Dir = "./"
+	cfg.Environment = map[string]string{"dataDir": t.TempDir()}
+	cfg.Routes = []*Route{
+		{
+			Method:   "GET",
+			HTTPPath: "/hello",
+			Handlers: handlers,
+		},
+	}
+
+	return
+}
+
+type describedPlugin struct {
+	BasePlugin
+
+	Handler *handlerPlugin `vroomy:"a"`
+}
+
+func (d *describedPlugin) Describe() PluginDescription {
+	return PluginDescription{
+		Name:        "Described",
+		Version:     "v1.0.0",
+		RequiredEnv: []string{"foo"},
+	}
+}
+
+func (d *describedPlugin) Backend() interface{} {
+	return d
+}
+
+func (h *handlerPlugin) Backend() interface{} {
+	return h
+}
+
+func TestVroomy_Plugins(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("b", &describedPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Note: Required environment keys are informational and do not prevent plugins from loading
+	cfg := newTestConfig(t, "a.Hello")
+	v, err := NewWithRegistry(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos := v.Plugins()
+	if len(infos) != 2 {
+		t.Fatalf("invalid number of plugins, expected %d and received %d", 2, len(infos))
+	}
+
+	a := infos[0]
+	if a.Key != "a" || a.Description != nil || a.State != PluginStateLoaded {
+		t.Fatalf("invalid plugin info for <a>: %+v", a)
+	}
+
+	if !stringSliceEqual(a.Handlers, []string{"Hello"}) {
+		t.Fatalf("invalid handlers, expected %v and received %v", []string{"Hello"}, a.Handlers)
+	}
+
+	b := infos[1]
+	if b.Key != "b" || b.Description == nil || b.Description.Version != "v1.0.0" {
+		t.Fatalf("invalid plugin info for <b>: %+v", b)
+	}
+
+	if !stringSliceEqual(b.Dependencies, []string{"a"}) {
+		t.Fatalf("invalid dependencies, expected %v and received %v", []string{"a"}, b.Dependencies)
+	}
+
+	if err = v.closePlugins(); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := v.Plugins()[0].State; state != PluginStateClosed {
+		t.Fatalf("invalid state, expected %v and received %v", PluginStateClosed, state)
+	}
+}
+
+func TestVroomy_backendCalls(t *testing.T) {
+	type testcase struct {
+		name string
+		// typed will register a plugin with a dependency which is resolved by type
+		typed         bool
+		expectedCalls int
+	}
+
+	tcs := []testcase{
+		// Backend is only called once the plugin has been loaded, when the backend is cached
+		{name: "untyped", expectedCalls: 1},
+		// Backend is called once for type resolution and once when the backend is cached
+		{name: "typed", typed: true, expectedCalls: 2},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRegistry()
+			cp := &countingPlugin{}
+			if err := r.Register("counting", cp); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Register("a", &handlerPlugin{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.typed {
+				if err := r.Register("optional", &optionalGreeterPlugin{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cp.calls != tc.expectedCalls {
+				t.Fatalf("invalid number of backend calls while loading, expected %d and received %d", tc.expectedCalls, cp.calls)
+			}
+
+			v.Plugins()
+			if _, err = v.DependencyGraph(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = v.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if cp.calls != tc.expectedCalls {
+				t.Fatalf("invalid number of backend calls after loading, expected %d and received %d", tc.expectedCalls, cp.calls)
+			}
+		})
+	}
+}
+
+type unloadedBackendPlugin struct {
+	BasePlugin
+
+	loaded bool
+}
+
+func (u *unloadedBackendPlugin) Load(env Environment) error {
+	u.loaded = true
+	return nil
+}
+
+func (u *unloadedBackendPlugin) Backend() interface{} {
+	if !u.loaded {
+		panic("backend called before the plugin was loaded")
+	}
+
+	return u
+}
+
+func TestVroomy_unloadedBackend(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("unloaded", &unloadedBackendPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backends are not introspected before Load when no dependencies are resolved by type
+	v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = v.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type lazyPlugin struct {
+	BasePlugin
+
+	Cache   *handlerPlugin       `vroomy:"cache,optional"`
+	Handler Lazy[*handlerPlugin] `vroomy:"a,lazy"`
+}
+
+func TestVroomy_lazyDependencies(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	lp := &lazyPlugin{}
+	if err := r.Register("b", lp); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r); err != nil {
+		t.Fatal(err)
+	}
+
+	if lp.Cache != nil {
+		t.Fatalf("invalid optional dependency, expected nil and received %v", lp.Cache)
+	}
+
+	h, err := lp.Handler.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h == nil {
+		t.Fatal("invalid lazy dependency, expected value and received nil")
+	}
+}
+
+type aggregatorPlugin struct {
+	BasePlugin
+
+	Greeters     []testGreeter          `vroomy:"*"`
+	GreetersByID map[string]testGreeter `vroomy:"*"`
+
+	loadedGreeters int
+}
+
+func (a *aggregatorPlugin) Load(env Environment) error {
+	a.loadedGreeters = len(a.Greeters)
+	return nil
+}
+
+func TestVroomy_collectionDependencies(t *testing.T) {
+	r := NewRegistry()
+	ap := &aggregatorPlugin{}
+	if err := r.Register("aggregator", ap); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("english", &greeterPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("spanish", &greeterPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ap.loadedGreeters != 2 {
+		t.Fatalf("invalid number of greeters during load, expected %d and received %d", 2, ap.loadedGreeters)
+	}
+
+	if _, ok := ap.GreetersByID["spanish"]; !ok || len(ap.GreetersByID) != 2 {
+		t.Fatalf("invalid greeters map, received %v", ap.GreetersByID)
+	}
+
+	g, err := v.DependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a", "english", "spanish", "aggregator"}; !stringSliceEqual(want, g.LoadOrder) {
+		t.Fatalf("invalid load order, expected %v and received %v", want, g.LoadOrder)
+	}
+}
+
+type multiFieldPlugin struct {
+	BasePlugin
+	AutoWire
+
+	Greeter     testGreeter
+	Named       *greeterPlugin    `vroomy:"greeter"`
+	Typed       testGreeter       `vroomy:",auto"`
+	LazyGreeter Lazy[testGreeter] `vroomy:"greeter,lazy"`
+}
+
+func TestVroomy_multipleFieldDependencies(t *testing.T) {
+	r := NewRegistry()
+	mp := &multiFieldPlugin{}
+	if err := r.Register("multi", mp); err != nil {
+		t.Fatal(err)
+	}
+
+	gp := &greeterPlugin{}
+	if err := r.Register("greeter", gp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lazy, err := mp.LazyGreeter.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case mp.Greeter != gp:
+		t.Fatalf("invalid auto-wired field, expected %p and received %v", gp, mp.Greeter)
+	case mp.Named != gp:
+		t.Fatalf("invalid keyed field, expected %p and received %v", gp, mp.Named)
+	case mp.Typed != gp:
+		t.Fatalf("invalid typed field, expected %p and received %v", gp, mp.Typed)
+	case lazy != gp:
+		t.Fatalf("invalid lazy field, expected %p and received %v", gp, lazy)
+	}
+
+	g, err := v.DependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The auto and keyed fields share an edge, the lazy field is a separate edge
+	if len(g.Edges) != 3 {
+		t.Fatalf("invalid number of edges, expected %d and received %d (%v)", 3, len(g.Edges), g.Edges)
+	}
+}
+
+type lateGreeterPlugin struct {
+	BasePlugin
+
+	greeter *greeterPlugin
+}
+
+func (l *lateGreeterPlugin) Load(env Environment) error {
+	l.greeter = &greeterPlugin{}
+	return nil
+}
+
+func (l *lateGreeterPlugin) Backend() interface{} {
+	if l.greeter == nil {
+		return nil
+	}
+
+	return l.greeter
+}
+
+type optionalGreeterPlugin struct {
+	BasePlugin
+
+	Greeter testGreeter `vroomy:",auto,optional"`
+}
+
+func TestVroomy_lateBackend(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("late", &lateGreeterPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("optional", &optionalGreeterPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "error loading plugin <late>: backend of <late> matches field <Greeter> of <optional> by type, but was not set before the plugins were loaded (backends resolved by type must be set when the plugin is initialized)"
+	if _, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r); err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+}
+
+type servicesPlugin struct {
+	BasePlugin
+
+	Env     Environment     `vroomy:"@env"`
+	Logger  *log.Logger     `vroomy:"@logger"`
+	Config  *Config         `vroomy:"@config"`
+	DataDir string          `vroomy:"@dataDir"`
+	Router  httpserve.Group `vroomy:"@router"`
+}
+
+func (s *servicesPlugin) Load(env Environment) error {
+	return s.Router.GET("/services", func(ctx *httpserve.Context) {
+		ctx.WriteNoContent()
+	})
+}
+
+type unknownServicePlugin struct {
+	BasePlugin
+
+	Foo string `vroomy:"@foo"`
+}
+
+func TestVroomy_serviceDependencies(t *testing.T) {
+	r := NewRegistry()
+	sp := &servicesPlugin{}
+	if err := r.Register("a", &handlerPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("services", sp); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t, "a.Hello")
+	if _, err := NewWithRegistry(cfg, r); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case sp.Env["dataDir"] != cfg.Environment["dataDir"]:
+		t.Fatalf("invalid environment, received %v", sp.Env)
+	case sp.Logger == nil || sp.Logger.Prefix() != "services: ":
+		t.Fatalf("invalid logger, received %v", sp.Logger)
+	case sp.Config != cfg:
+		t.Fatalf("invalid config, expected %p and received %p", cfg, sp.Config)
+	case sp.DataDir != cfg.Environment["dataDir"]:
+		t.Fatalf("invalid data directory, expected <%s> and received <%s>", cfg.Environment["dataDir"], sp.DataDir)
+	}
+
+	if err := r.Register("unknown", &unknownServicePlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "error validating dependencies of <unknown>: invalid reserved dependency <@foo> for field <Foo>"
+	if _, err := NewWithRegistry(newTestConfig(t, "a.Hello"), r); err == nil || err.Error() != want {
+		t.Fatalf("invalid error, expected <%s> and received <%v>", want, err)
+	}
+}
+
+func TestVroomy_getHTTPHandler(t *testing.T) {
+	type testcase struct {
+		cfg              Config
+		https            net.Listener
+		expectedLocation string
+		expectedErr      string
+	}
+
+	tcpListener, err := net.Listen(networkTCP, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpListener.Close()
+
+	loc := filepath.Join(t.TempDir(), "app.sock")
+	unixListener, err := net.Listen(networkUnix, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unixListener.Close()
+
+	tlsPort := tcpListener.Addr().(*net.TCPAddr).Port
+	tcs := []testcase{
+		{cfg: Config{Port: 8080}},
+		{cfg: Config{Port: 8080, TLSPort: 8443, AllowNonTLS: true}, https: tcpListener},
+		{
+			// The bound port of the HTTPS listener is used rather than the configured port or address
+			cfg:              Config{Port: 8080, TLSListen: "127.0.0.1:0"},
+			https:            tcpListener,
+			expectedLocation: fmt.Sprintf("https://example.com:%d/hello?name=world", tlsPort),
+		},
+		{
+			cfg:         Config{Port: 8080},
+			https:       unixListener,
+			expectedErr: "cannot redirect HTTP requests to the HTTPS listener on unix://" + loc + ", allowNonTLS must be set",
+		},
+	}
+
+	for _, tc := range tcs {
+		var v Vroomy
+		v.cfg = &tc.cfg
+		v.handler.set(newRouter())
+		if tc.https != nil {
+			v.listeners = map[string]net.Listener{ListenerHTTPS: tc.https}
+		}
+
+		h, err := v.getHTTPHandler()
+		if errStr := getErrorString(err); errStr != tc.expectedErr {
+			t.Fatalf("invalid error, expected <%s> and received <%s>", tc.expectedErr, errStr)
+		} else if err != nil {
+			continue
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com:8080/hello?name=world", nil))
+		if len(tc.expectedLocation) == 0 {
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("invalid status code, expected the router to serve the request with %d and received %d", http.StatusNotFound, rec.Code)
+			}
+
+			continue
+		}
+
+		if rec.Code != http.StatusMovedPermanently {
+			t.Fatalf("invalid status code, expected %d and received %d", http.StatusMovedPermanently, rec.Code)
+		}
+
+		if location := rec.Header().Get("Location"); location != tc.expectedLocation {
+			t.Fatalf("invalid location, expected <%s> and received <%s>", tc.expectedLocation, location)
+		}
+	}
+}